@@ -0,0 +1,220 @@
+package gli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// findCommandPrefix returns sub commands (and extras) having a name beginning with prefix,
+// and the longest names of them. Hidden commands need exact names.
+func (c *command) findCommandPrefix(prefix string) (cmds []*command, names []string) {
+	for _, s := range c.visibleSubs(false) {
+		if n := longestPrefixed(s.names, prefix, 1); n != "" {
+			cmds = append(cmds, s)
+			names = append(names, n)
+		}
+	}
+	return cmds, names
+}
+
+// findOptionPrefix returns options having a long name beginning with prefix,
+// and the longest names of them. Hidden options need exact names.
+func (c *command) findOptionPrefix(prefix string, boolOnly bool) (opts []*option, names []string) {
+	for _, o := range c.visibleOptions(false) {
+		if boolOnly && o.takesArg() {
+			continue
+		}
+		if n := longestPrefixed(o.names, prefix, 2); n != "" {
+			opts = append(opts, o)
+			names = append(names, n)
+		}
+	}
+	return opts, names
+}
+
+// longestPrefixed returns the longest one of names beginning with prefix, at least minLen long.
+func longestPrefixed(names []string, prefix string, minLen int) string {
+	var found string
+	for _, n := range names {
+		if len(n) >= minLen && len(n) > len(found) && strings.HasPrefix(n, prefix) {
+			found = n
+		}
+	}
+	return found
+}
+
+// preprocessArgs rewrites args before parsing.
+//
+// If AllowAbbrev, it replaces unique prefixes of sub command names and long option names with full names.
+//
+//	a.out li --verb     ->  a.out list --verbose
+//	a.out --no-col      ->  a.out --no-color
+//
+// Values of counters (gli.Count) are taken out into counts, by indices of args.
+//
+//	a.out --verbose=3   ->  a.out --verbose  (counts[0] = "3")
+//
+// Options missing their values (KindMissingValue)
+// and options without values given ones (--verbose=x) are reported before parsing.
+//
+// Options are looked up in the command at that position.
+// As the parser does, args after the first positional argument (or --) are left as they are.
+func (g App) preprocessArgs(args []string) (result []string, counts map[int]string, err error) {
+	result = append([]string{}, args...)
+	counts = make(map[int]string)
+
+	cmd := g.root
+
+	// value of result[i] is result[i+1], unless it is the end, -- or a sub command
+	missingValue := func(o *option, name string, i int) error {
+		if i+1 < len(result) {
+			next := result[i+1]
+			sub, _ := cmd.findCommandExact(next)
+			if sub == nil && !(g.DoubleHyphen && next == "--") && !(next == "help" && len(cmd.subs) > 0) {
+				return nil
+			}
+		}
+		return newParseError(KindMissingValue, o.longestName(), cmd, i, fmt.Errorf("option %q without arguments", name))
+	}
+	unexpectedValue := func(o *option, name string, i int) error {
+		return newParseError(KindDecodeFailure, o.longestName(), cmd, i, fmt.Errorf("option %q must not have an argument", name))
+	}
+
+	for i := 0; i < len(result); i++ {
+		a := result[i]
+
+		if g.DoubleHyphen && a == "--" {
+			break
+		}
+
+		switch {
+		case strings.HasPrefix(a, "--") && len(a) > 2:
+			nv := strings.SplitN(a[2:], "=", 2)
+			name := nv[0]
+
+			o := cmd.findOptionExact(name)
+			if o == nil && g.AllowAbbrev && len(name) > 1 {
+				var lname string
+				o, lname, err = g.expandOption(name, cmd, i)
+				if err != nil {
+					return nil, nil, err
+				}
+				if o != nil {
+					nv[0] = lname
+					result[i] = "--" + strings.Join(nv, "=")
+				}
+			}
+
+			if o != nil && o.counter && len(nv) == 2 {
+				counts[i] = nv[1]
+				result[i] = "--" + nv[0]
+			}
+
+			if o != nil && o.takesArg() && len(nv) == 1 {
+				if err := missingValue(o, nv[0], i); err != nil {
+					return nil, nil, err
+				}
+				i++ // --opt VALUE
+			}
+			if o != nil && !o.takesArg() && !o.counter && len(nv) == 2 {
+				return nil, nil, unexpectedValue(o, nv[0], i)
+			}
+
+		case strings.HasPrefix(a, "-") && len(a) > 1:
+			nv := strings.SplitN(a[1:], "=", 2)
+			name := nv[0]
+			if o := cmd.findOptionExact(name); o != nil && o.counter && len(nv) == 2 {
+				counts[i] = nv[1]
+				result[i] = "-" + name
+				continue
+			}
+			if o := cmd.findOptionExact(name); o != nil || !g.OptionsGrouped {
+				switch {
+				case o == nil:
+				case o.takesArg() && len(nv) == 1:
+					if err := missingValue(o, name, i); err != nil {
+						return nil, nil, err
+					}
+					i++ // -opt VALUE
+				case !o.takesArg() && !o.counter && len(nv) == 2:
+					return nil, nil, unexpectedValue(o, name, i)
+				}
+				continue
+			}
+			for ci, r := range name {
+				o := cmd.findOptionExact(string(r))
+				if o == nil || !o.takesArg() {
+					continue
+				}
+				if ci < len(name)-1 {
+					// -fv (f takes a value)
+					return nil, nil, newParseError(KindMissingValue, o.longestName(), cmd, i, fmt.Errorf("option %q without arguments", string(r)))
+				}
+				if len(nv) == 1 {
+					if err := missingValue(o, string(r), i); err != nil {
+						return nil, nil, err
+					}
+					i++ // -abc VALUE
+				}
+				break
+			}
+
+		case a == "help":
+			// a.out help sub
+
+		default:
+			if len(cmd.subs)+len(cmd.extras) == 0 {
+				return result, counts, nil // an arg
+			}
+
+			if sub, _ := cmd.findCommandExact(a); sub != nil {
+				cmd = sub
+				continue
+			}
+			if !g.AllowAbbrev {
+				return result, counts, nil // an arg
+			}
+
+			cmds, names := cmd.findCommandPrefix(a)
+			switch len(cmds) {
+			case 0:
+				return result, counts, nil // an arg
+			case 1:
+				result[i] = names[0]
+				cmd = cmds[0]
+			default:
+				sort.Strings(names)
+				perr := newParseError(KindAmbiguous, a, cmd, i, fmt.Errorf("command %s is ambiguous: %s", a, strings.Join(names, ", ")))
+				perr.Suggestions = names
+				return nil, nil, perr
+			}
+		}
+	}
+
+	return result, counts, nil
+}
+
+// expandOption finds an option by a prefix of a long name, or --no- and a prefix.
+func (g App) expandOption(name string, cmd *command, index int) (*option, string, error) {
+	opts, names := cmd.findOptionPrefix(name, false)
+
+	if len(opts) == 0 && g.AutoNoBoolOptions && strings.HasPrefix(name, "no-") {
+		opts, names = cmd.findOptionPrefix(name[3:], true)
+		for i := range names {
+			names[i] = "no-" + names[i]
+		}
+	}
+
+	switch len(opts) {
+	case 0:
+		return nil, "", nil
+	case 1:
+		return opts[0], names[0], nil
+	default:
+		sort.Strings(names)
+		perr := newParseError(KindAmbiguous, name, cmd, index, fmt.Errorf("option %s is ambiguous: %s", name, strings.Join(names, ", ")))
+		perr.Suggestions = names
+		return nil, "", perr
+	}
+}
@@ -0,0 +1,253 @@
+package gli
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// posArg is a positional argument bound to a field.
+//
+//	type copyCmd struct {
+//	    Src   string   `arg:"0" required:"true"`
+//	    Dst   string   `arg:"1=DEST"`
+//	    Files []string `args:"rest"`
+//	}
+type posArg struct {
+	name  string
+	index int // position; ignored if rest
+	rest  bool
+
+	required   bool
+	help       string
+	tag        reflect.StructTag
+	validation *validation // for each element if rest
+
+	fieldIdx []int
+	typ      reflect.Type
+}
+
+// parseArgTag parses `arg:"N"`, `arg:"N=NAME"`, `args:"rest"` and `args:"rest=NAME"`.
+func (g *App) parseArgTag(ft reflect.StructField, path []int) (*posArg, bool, error) {
+	tv, isarg := ft.Tag.Lookup(g.ArgTag)
+	rv, isrest := ft.Tag.Lookup(g.ArgsTag)
+	if isrest && strings.TrimSpace(strings.SplitN(rv, "=", 2)[0]) != "rest" {
+		// args:"1..3" and so on
+		if _, err := parseArity(rv); err != nil {
+			return nil, false, errors.Wrapf(err, "field %s", ft.Name)
+		}
+		isrest = false
+	}
+	if !isarg && !isrest {
+		return nil, false, nil
+	}
+
+	a := &posArg{
+		rest:     isrest,
+		help:     strings.TrimSpace(ft.Tag.Get(g.HelpTag)),
+		tag:      ft.Tag,
+		fieldIdx: path,
+		typ:      ft.Type,
+	}
+
+	if isrest {
+		tv = rv
+	}
+	nn := strings.SplitN(tv, "=", 2)
+	if len(nn) == 2 {
+		a.name = strings.TrimSpace(nn[1])
+	}
+	if a.name == "" {
+		a.name = strings.ToUpper(g.arrangeName(ft.Name, false))
+	}
+
+	if isrest {
+		if ft.Type.Kind() != reflect.Slice {
+			return nil, true, fmt.Errorf("args %s: rest must be a slice", ft.Name)
+		}
+	} else {
+		idx, err := strconv.Atoi(strings.TrimSpace(nn[0]))
+		if err != nil || idx < 0 {
+			return nil, true, fmt.Errorf("arg %s: invalid position %q", ft.Name, nn[0])
+		}
+		a.index = idx
+	}
+
+	if required, err := strconv.ParseBool(strings.TrimSpace(ft.Tag.Get(g.RequiredTag))); err == nil {
+		a.required = required
+	}
+
+	vt := ft.Type
+	if isrest {
+		vt = vt.Elem()
+	}
+	v, err := g.parseValidation(vt, ft.Tag)
+	if err != nil {
+		return nil, true, errors.Wrapf(err, "field %s", ft.Name)
+	}
+	a.validation = v
+
+	return a, true, nil
+}
+
+// usageName is NAME, [NAME], NAME... or [NAME...]
+func (a posArg) usageName() string {
+	n := a.name
+	if a.rest {
+		n += "..."
+	}
+	if !a.required {
+		n = "[" + n + "]"
+	}
+	return n
+}
+
+// sortArgs sorts positional args by positions, the rest last.
+func (c *command) sortArgs() {
+	sort.SliceStable(c.posArgs, func(i, j int) bool {
+		a, b := c.posArgs[i], c.posArgs[j]
+		if a.rest != b.rest {
+			return b.rest
+		}
+		return a.index < b.index
+	})
+}
+
+// argsUsage returns like "SRC [DEST] [FILES...]".
+func (c *command) argsUsage() string {
+	var names []string
+	for _, a := range c.posArgs {
+		names = append(names, a.usageName())
+	}
+	return strings.Join(names, " ")
+}
+
+// setArgValues decodes c.args into fields bound by arg/args tags.
+func (c *command) setArgValues(dectypeTag string) error {
+	if len(c.posArgs) == 0 {
+		return nil
+	}
+
+	hasRest := false
+	maxIdx := -1
+	for _, a := range c.posArgs {
+		if a.rest {
+			hasRest = true
+		} else if maxIdx < a.index {
+			maxIdx = a.index
+		}
+	}
+	if !hasRest {
+		a := arity{min: 0, max: maxIdx + 1}
+		if err := a.check(len(c.args)); err != nil {
+			return newParseError(KindArgCount, "", c, c.argvIndexOf(maxIdx+1), err)
+		}
+	}
+
+	for _, a := range c.posArgs {
+		fv := c.selfV.Elem().FieldByIndex(a.fieldIdx)
+
+		if a.rest {
+			start := maxIdx + 1
+			if start >= len(c.args) {
+				if a.required {
+					return newParseError(KindMissingRequired, a.name, c, -1, errors.New("argument "+a.name+" is required"))
+				}
+				continue
+			}
+
+			rest := reflect.MakeSlice(a.typ, 0, len(c.args)-start)
+			for i := start; i < len(c.args); i++ {
+				ev := reflect.New(a.typ.Elem()).Elem()
+				first := true
+				if err := setOptValue(ev, c.args[i], a.tag, a.validation, dectypeTag, false, &first); err != nil {
+					return newParseError(valueErrorKind(err), a.name, c, c.argvIndexOf(i), errors.Wrap(err, "argument "+a.name))
+				}
+				rest = reflect.Append(rest, ev)
+			}
+			fv.Set(rest)
+			continue
+		}
+
+		if a.index >= len(c.args) {
+			if a.required {
+				return newParseError(KindMissingRequired, a.name, c, -1, errors.New("argument "+a.name+" is required"))
+			}
+			continue
+		}
+
+		first := true
+		if err := setOptValue(fv, c.args[a.index], a.tag, a.validation, dectypeTag, false, &first); err != nil {
+			return newParseError(valueErrorKind(err), a.name, c, c.argvIndexOf(a.index), errors.Wrap(err, "argument "+a.name))
+		}
+	}
+
+	return nil
+}
+
+// arity is a constraint on the number of arguments (`args:"1..3"`).
+type arity struct {
+	min, max int // max < 0: unlimited
+}
+
+// parseArity parses "N", "N..M", "N.." and "..M".
+func parseArity(s string) (*arity, error) {
+	s = strings.TrimSpace(s)
+
+	a := &arity{min: 0, max: -1}
+	var err error
+
+	if pos := strings.Index(s, ".."); pos == -1 {
+		a.min, err = strconv.Atoi(s)
+		a.max = a.min
+	} else {
+		if mins := strings.TrimSpace(s[:pos]); mins != "" {
+			a.min, err = strconv.Atoi(mins)
+		}
+		if maxs := strings.TrimSpace(s[pos+2:]); err == nil && maxs != "" {
+			a.max, err = strconv.Atoi(maxs)
+		}
+	}
+
+	if err != nil || a.min < 0 || a.max >= 0 && a.max < a.min {
+		return nil, fmt.Errorf("args: invalid range %q", s)
+	}
+	return a, nil
+}
+
+func (a arity) String() string {
+	switch {
+	case a.min == a.max:
+		return fmt.Sprintf("exactly %d", a.min)
+	case a.max < 0:
+		return fmt.Sprintf("at least %d", a.min)
+	case a.min == 0:
+		return fmt.Sprintf("at most %d", a.max)
+	default:
+		return fmt.Sprintf("%d to %d", a.min, a.max)
+	}
+}
+
+func (a arity) check(n int) error {
+	if n < a.min || a.max >= 0 && n > a.max {
+		return errors.Errorf("wrong number of arguments: %d given, %s expected", n, a)
+	}
+	return nil
+}
+
+// Args is an optional argument to AddExtraCommand.
+// It constrains the number of arguments like `args:"1..3"`.
+// It panics if rng is not a valid range, as AddExtraCommand does for invalid commands.
+func Args(rng string) extraCmdInit {
+	a, err := parseArity(rng)
+	if err != nil {
+		panic(err.Error())
+	}
+	return func(c *command) {
+		c.arity = a
+	}
+}
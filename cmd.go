@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	runewidth "github.com/mattn/go-runewidth"
+	"github.com/pkg/errors"
 )
 
 type command struct {
@@ -20,15 +21,28 @@ type command struct {
 	extras []*command
 
 	options []*option
+	posArgs []*posArg
+	arity   *arity
 
-	args []string
+	requiresAny []string
+
+	args   []string
+	argIdx []int // indices of args in argv
 
 	help  string
 	usage string
+	tag   reflect.StructTag
+
+	deprecated  bool
+	deprecation string // a message like "use new-name"
+	hidden      bool
+	category    string // a section in help
 
-	selfV    reflect.Value
-	ownerV   reflect.Value
-	fieldIdx int
+	selfV     reflect.Value
+	selfT     reflect.Type
+	ownerV    reflect.Value
+	fieldIdx  int
+	fieldPath []int
 
 	autoNoBoolOptions bool
 }
@@ -67,6 +81,14 @@ func (c command) longestNameStack() []string {
 	return s
 }
 
+// argvIndexOf returns the index in argv of c.args[i], or -1.
+func (c *command) argvIndexOf(i int) int {
+	if i < 0 || i >= len(c.argIdx) {
+		return -1
+	}
+	return c.argIdx[i]
+}
+
 func (c *command) findOptionExact(name string) *option {
 	for _, o := range c.options {
 		for _, n := range o.names {
@@ -96,6 +118,46 @@ func (c *command) findCommandExact(name string) (cmd *command, isextra bool) {
 	return nil, false
 }
 
+// hasHook reports whether the command struct has a hook method funcName.
+func (c *command) hasHook(funcName string) bool {
+	if c.selfT == nil {
+		return false
+	}
+	_, ok := reflect.PtrTo(c.selfT).MethodByName(funcName)
+	return ok
+}
+
+// walkVisible calls f for c and its descendants (subs and extras), parents first,
+// except hidden commands (and their descendants).
+func (c *command) walkVisible(f func(*command)) {
+	f(c)
+	for _, s := range c.visibleSubs(false) {
+		s.walkVisible(f)
+	}
+}
+
+// visibleSubs returns sub commands followed by extra commands, except hidden ones unless all.
+func (c command) visibleSubs(all bool) []*command {
+	var subs []*command
+	for _, s := range append(append([]*command{}, c.subs...), c.extras...) {
+		if all || !s.hidden {
+			subs = append(subs, s)
+		}
+	}
+	return subs
+}
+
+// visibleOptions returns options except hidden ones unless all.
+func (c command) visibleOptions(all bool) []*option {
+	var opts []*option
+	for _, o := range c.options {
+		if all || !o.hidden {
+			opts = append(opts, o)
+		}
+	}
+	return opts
+}
+
 func (c *command) setMembersReferMe() {
 	for _, o := range c.options {
 		o.ownerV = c.selfV
@@ -105,248 +167,325 @@ func (c *command) setMembersReferMe() {
 	}
 }
 
-func (c *command) setDefaultValues(dectypeTag string) {
+// setDefaultValues assigns values of default tags, config and env tags in this order.
+// Decoding errors of default and env tags are ignored, but validation errors are not.
+// Values in error are skipped, and the first error is returned after all options are processed.
+func (c *command) setDefaultValues(dectypeTag string, config map[string]string) error {
+	var firstErr error
+	fail := func(err error) {
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
 	for _, o := range c.options {
+		var verr *ValidationError
+
 		if o.defValue != "" {
 			var dummy bool
 			fv := o.ownerV.Elem().FieldByIndex(o.fieldIdx)
-			_ = setOptValue(fv, o.defValue, o.tag, dectypeTag, true, &dummy)
-			o.assigned = true
+			err := setOptValue(fv, o.defValue, o.tag, o.validation, dectypeTag, true, &dummy)
+			if errors.As(err, &verr) {
+				fail(newParseError(KindInvalidValue, o.longestName(), c, -1, errors.Wrap(err, "default of option "+o.longestName())))
+			} else {
+				o.assigned = true
+				o.defaulted = true
+			}
+		}
+		if o.configKey != "" {
+			if configvalue, found := config[o.configKey]; found {
+				first := true
+				fv := o.ownerV.Elem().FieldByIndex(o.fieldIdx)
+				err := setOptValue(fv, configvalue, o.tag, o.validation, dectypeTag, true, &first)
+				if err != nil {
+					fail(newParseError(valueErrorKind(err), o.longestName(), c, -1, errors.Wrap(err, "config "+o.configKey)))
+				} else {
+					o.assigned = true
+					o.defaulted = false
+				}
+			}
 		}
 		if o.env != "" {
 			envvalue := os.Getenv(o.env)
 			if envvalue != "" {
-				var dummy bool
+				first := true
 				fv := o.ownerV.Elem().FieldByIndex(o.fieldIdx)
-				_ = setOptValue(fv, envvalue, o.tag, dectypeTag, true, &dummy)
-				o.assigned = true
+				err := setOptValue(fv, envvalue, o.tag, o.validation, dectypeTag, true, &first)
+				if errors.As(err, &verr) {
+					fail(newParseError(KindInvalidValue, o.longestName(), c, -1, errors.Wrap(err, "env "+o.env)))
+				} else {
+					o.assigned = true
+					o.defaulted = false
+				}
 			}
 		}
 	}
+
+	return firstErr
 }
 
-func (c command) outputHelp(w io.Writer) {
+// outputHelp writes help of the command, wrapping and colorizing by f.
+func (c command) outputHelp(w io.Writer, f helpFormat) {
+	th := f.theme
+
 	if len(c.names) > 0 {
 		name := longestName(c.names)
-		fmt.Fprintf(w, "command %s - %s\n", name, c.help)
+		writeWrapped(w, paint(th.Header, "command")+" "+paint(th.Command, name)+" - ", c.help, f.width)
 	}
 
-	if len(c.subs)+len(c.extras) > 0 {
-		fmt.Fprintln(w)
-		fmt.Fprintln(w, "Sub commands:")
-
-		var subs []*command
-		subs = append(subs, c.subs...)
-		subs = append(subs, c.extras...)
-
+	if subs := c.visibleSubs(f.all); len(subs) > 0 {
 		var names []string
 		var helps []string
-		width := 0
+		var categories []string
+		namewidth := 0
 		for _, s := range subs {
 			snames := s.names
 			sort.Slice(snames, func(i, j int) bool { return len(snames[i]) > len(snames[j]) })
 			n := strings.Join(s.names, ", ")
 			names = append(names, n)
-			helps = append(helps, s.help)
+			var notes []string
+			if s.hidden {
+				notes = append(notes, "hidden")
+			}
+			if note := deprecationNote(s.deprecated, s.deprecation); note != "" {
+				notes = append(notes, note)
+			}
+			if len(notes) > 0 {
+				helps = append(helps, strings.TrimLeft(s.help+" "+paint(th.Default, "("+strings.Join(notes, " ")+")"), " "))
+			} else {
+				helps = append(helps, s.help)
+			}
+			categories = append(categories, s.category)
 
 			w := runewidth.StringWidth(n)
-			if width < w {
-				width = w
+			if namewidth < w {
+				namewidth = w
 			}
 		}
 
-		width += 2
+		namewidth += 2
 
-		for i, n := range names {
-			spaces := strings.Repeat(" ", width-len(n))
-			fmt.Fprintf(w, "  %s%s%s\n", n, spaces, helps[i])
+		titles, members := helpSections(categories)
+		for si, title := range titles {
+			if title == "" {
+				title = "Sub commands"
+			}
+			fmt.Fprintln(w)
+			fmt.Fprintln(w, paint(th.Header, title+":"))
+
+			for _, i := range members[si] {
+				n := names[i]
+				spaces := strings.Repeat(" ", namewidth-runewidth.StringWidth(n))
+				writeWrapped(w, "  "+paint(th.Command, n)+spaces, helps[i], f.width)
+			}
 		}
 	}
 
-	if len(c.options) > 0 {
+	if len(c.posArgs) > 0 || c.arity != nil {
 		fmt.Fprintln(w)
-		fmt.Fprintln(w, "Options:")
+		if c.arity != nil {
+			fmt.Fprintln(w, paint(th.Header, fmt.Sprintf("Arguments (%s):", c.arity)))
+		} else {
+			fmt.Fprintln(w, paint(th.Header, "Arguments:"))
+		}
 
-		var names []string
-		var nonames []string
-		var helps []string
-		var defdesc []string
-		var envs []string
-		width := 0
+		namewidth := 0
+		for _, a := range c.posArgs {
+			if aw := runewidth.StringWidth(a.usageName()); namewidth < aw {
+				namewidth = aw
+			}
+		}
+		namewidth += 2
 
-		for _, o := range c.options {
+		for _, a := range c.posArgs {
+			n := a.usageName()
+			spaces := strings.Repeat(" ", namewidth-runewidth.StringWidth(n))
+			writeWrapped(w, "  "+paint(th.Placeholder, n)+spaces, a.help, f.width)
+		}
+	}
 
-			var onames []string
-			onames = append(onames, o.names...)
-			for i, n := range onames {
-				if len(n) == 1 {
-					onames[i] = "-" + n
-				} else {
-					onames[i] = "--" + n
-				}
-			}
+	if opts := c.visibleOptions(f.all); len(opts) > 0 {
+		c.outputOptionsHelp(w, "Options", opts, true, f)
+	}
 
-			sort.Slice(onames, func(i, j int) bool { return len(onames[i]) < len(onames[j]) })
-			n := strings.Join(onames, ", ")
-			if o.placeholder != "" {
-				n += " " + o.placeholder
+	curr := &c
+	for {
+		curr = curr.parent
+		if curr == nil {
+			break
+		}
+
+		if opts := curr.visibleOptions(f.all); len(opts) > 0 {
+			currname := "Global"
+			if len(curr.names) > 0 {
+				currname = "Outer " + curr.names[0]
 			}
-			names = append(names, n)
-			fv := o.ownerV.Elem().FieldByIndex(o.fieldIdx)
-			if c.autoNoBoolOptions && fv.Type().Kind() == reflect.Bool {
-				b, err := strconv.ParseBool(o.defValue)
-				if err != nil {
-					b = false
-				}
-				if b {
-					oname := strings.TrimLeft(onames[len(onames)-1], "-")
-					nonames = append(nonames, "--no-"+oname)
-				} else {
-					nonames = append(nonames, "")
-				}
+
+			curr.outputOptionsHelp(w, currname+" Options", opts, false, f)
+		}
+	}
+
+	usage := strings.TrimSpace(c.usage)
+	if usage == "" && len(c.posArgs) > 0 {
+		usage = strings.Join(append(c.longestNameStack(), "[options]", c.argsUsage()), " ")
+	}
+	if len(usage) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, paint(th.Header, "Usage:"))
+		for _, l := range strings.Split(usage, "\n") {
+			writeIndented(w, "  ", "    ", l, f.width)
+		}
+	}
+}
+
+// outputOptionsHelp writes the list of opts of the command, titled.
+// If grouped, options having the group tag are in sections titled by the group.
+func (c command) outputOptionsHelp(w io.Writer, title string, opts []*option, grouped bool, f helpFormat) {
+	th := f.theme
+
+	var names []string
+	var labels []string
+	var nonames []string
+	var helps []string
+	var defdesc []string
+	var envs []string
+	var xors []string
+	var deprecations []string
+	var hiddens []bool
+	var counters []bool
+	var groups []string
+	namewidth := 0
+
+	for _, o := range opts {
+		var onames []string
+		onames = append(onames, o.names...)
+		for i, n := range onames {
+			if len(n) == 1 {
+				onames[i] = "-" + n
 			} else {
-				nonames = append(nonames, "")
+				onames[i] = "--" + n
 			}
+		}
 
-			helps = append(helps, o.help)
-			if o.defDesc != "" {
-				defdesc = append(defdesc, o.defDesc)
+		sort.Slice(onames, func(i, j int) bool { return len(onames[i]) < len(onames[j]) })
+		n := strings.Join(onames, ", ")
+		label := paint(th.Option, n)
+		if o.placeholder != "" {
+			n += " " + o.placeholder
+			label += " " + paint(th.Placeholder, o.placeholder)
+		}
+		names = append(names, n)
+		labels = append(labels, label)
+		fv := o.ownerV.Elem().FieldByIndex(o.fieldIdx)
+		if c.autoNoBoolOptions && fv.Type().Kind() == reflect.Bool {
+			b, err := strconv.ParseBool(o.defValue)
+			if err != nil {
+				b = false
+			}
+			if b {
+				oname := strings.TrimLeft(onames[len(onames)-1], "-")
+				nonames = append(nonames, "--no-"+oname)
 			} else {
-				defdesc = append(defdesc, o.defValue)
+				nonames = append(nonames, "")
 			}
-			envs = append(envs, o.env)
+		} else {
+			nonames = append(nonames, "")
+		}
 
-			w := runewidth.StringWidth(n)
-			if width < w {
-				width = w
-			}
+		helps = append(helps, o.help)
+		if o.defDesc != "" {
+			defdesc = append(defdesc, o.defDesc)
+		} else {
+			defdesc = append(defdesc, o.defValue)
 		}
+		envs = append(envs, o.env)
+		xors = append(xors, strings.Join(o.xor, ","))
+		deprecations = append(deprecations, deprecationNote(o.deprecated, o.deprecation))
+		hiddens = append(hiddens, o.hidden)
+		counters = append(counters, o.counter)
+		if grouped {
+			groups = append(groups, o.group)
+		} else {
+			groups = append(groups, "")
+		}
+
+		w := runewidth.StringWidth(n)
+		if namewidth < w {
+			namewidth = w
+		}
+	}
+
+	namewidth += 2
 
-		width += 2
+	titles, members := helpSections(groups)
+	for si, t := range titles {
+		if t == "" {
+			t = title
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, paint(th.Header, t+":"))
 
-		for i, n := range names {
-			spaces := strings.Repeat(" ", width-runewidth.StringWidth(n))
+		for _, i := range members[si] {
+			spaces := strings.Repeat(" ", namewidth-runewidth.StringWidth(names[i]))
 
 			var des []string
+			if hiddens[i] {
+				des = append(des, "hidden")
+			}
+			if len(deprecations[i]) > 0 {
+				des = append(des, deprecations[i])
+			}
+			if counters[i] {
+				des = append(des, "repeatable")
+			}
 			if len(defdesc[i]) > 0 {
 				des = append(des, "default: "+defdesc[i])
 			}
 			if len(envs[i]) > 0 {
 				des = append(des, "env: "+envs[i])
 			}
+			if len(xors[i]) > 0 {
+				des = append(des, "xor: "+xors[i])
+			}
 			var de string
 			if len(des) > 0 {
-				de = " (" + strings.Join(des, " ") + ")"
+				de = " " + paint(th.Default, "("+strings.Join(des, " ")+")")
 			}
 
-			fmt.Fprintf(w, "  %s%s%s%s\n", n, spaces, helps[i], de)
+			writeWrapped(w, "  "+labels[i]+spaces, helps[i]+de, f.width)
 
 			if nonames[i] != "" {
-				fmt.Fprintf(w, "    %s\n", nonames[i])
+				fmt.Fprintf(w, "    %s\n", paint(th.Option, nonames[i]))
 			}
 		}
 	}
+}
 
-	curr := &c
-	for {
-		curr = curr.parent
-		if curr == nil {
-			break
-		}
-
-		if len(curr.options) > 0 {
-			currname := "Global"
-			if len(curr.names) > 0 {
-				currname = "Outer " + curr.names[0]
-			}
-
-			fmt.Fprintln(w)
-			fmt.Fprintf(w, "%s Options:\n", currname)
-
-			var names []string
-			var nonames []string
-			var helps []string
-			var defdesc []string
-			var envs []string
-			width := 0
-
-			for _, o := range curr.options {
-				var onames []string
-				onames = append(onames, o.names...)
-				for i, n := range onames {
-					if len(n) == 1 {
-						onames[i] = "-" + n
-					} else {
-						onames[i] = "--" + n
-					}
-				}
-
-				sort.Slice(onames, func(i, j int) bool { return len(onames[i]) < len(onames[j]) })
-				n := strings.Join(onames, ", ")
-				if o.placeholder != "" {
-					n += " " + o.placeholder
-				}
-				names = append(names, n)
-				fv := o.ownerV.Elem().FieldByIndex(o.fieldIdx)
-				if curr.autoNoBoolOptions && fv.Type().Kind() == reflect.Bool {
-					b, err := strconv.ParseBool(o.defValue)
-					if err != nil {
-						b = false
-					}
-					if b {
-						oname := strings.TrimLeft(onames[len(onames)-1], "-")
-						nonames = append(nonames, "--no-"+oname)
-					} else {
-						nonames = append(nonames, "")
-					}
-				} else {
-					nonames = append(nonames, "")
-				}
-
-				helps = append(helps, o.help)
-				if o.defDesc != "" {
-					defdesc = append(defdesc, o.defDesc)
-				} else {
-					defdesc = append(defdesc, o.defValue)
-				}
-				envs = append(envs, o.env)
-
-				w := runewidth.StringWidth(n)
-				if width < w {
-					width = w
-				}
-			}
-
-			width += 2
-
-			for i, n := range names {
-				spaces := strings.Repeat(" ", width-runewidth.StringWidth(n))
-
-				var des []string
-				if len(defdesc[i]) > 0 {
-					des = append(des, "default: "+defdesc[i])
-				}
-				if len(envs[i]) > 0 {
-					des = append(des, "env: "+envs[i])
-				}
-				var de string
-				if len(des) > 0 {
-					de = " (" + strings.Join(des, " ") + ")"
-				}
-
-				fmt.Fprintf(w, "  %s%s%s%s\n", n, spaces, helps[i], de)
+// helpSections groups indices of titles by title, in order of first appearance.
+// The untitled section ("") comes first.
+func helpSections(titles []string) (sections []string, members [][]int) {
+	pos := make(map[string]int)
+	if len(titles) > 0 {
+		pos[""] = 0
+		sections = append(sections, "")
+		members = append(members, nil)
+	}
 
-				if nonames[i] != "" {
-					fmt.Fprintf(w, "    %s\n", nonames[i])
-				}
-			}
+	for i, t := range titles {
+		p, found := pos[t]
+		if !found {
+			p = len(sections)
+			pos[t] = p
+			sections = append(sections, t)
+			members = append(members, nil)
 		}
+		members[p] = append(members[p], i)
 	}
 
-	if len(c.usage) > 0 {
-		fmt.Fprintln(w)
-		fmt.Fprintf(w, "Usage:\n  %v\n", strings.Replace(strings.TrimSpace(c.usage), "\n", "\n  ", -1))
+	if len(members) > 0 && len(members[0]) == 0 {
+		sections, members = sections[1:], members[1:]
 	}
+	return sections, members
 }
 
 type extraCmdInit func(*command)
@@ -358,6 +497,39 @@ func Usage(usage string) extraCmdInit {
 	}
 }
 
+// Hidden is an optional argument to AddExtracommand, like the hidden tag.
+func Hidden() extraCmdInit {
+	return func(c *command) {
+		c.hidden = true
+	}
+}
+
+// Category is an optional argument to AddExtracommand, like the category tag.
+func Category(category string) extraCmdInit {
+	return func(c *command) {
+		c.category = strings.TrimSpace(category)
+	}
+}
+
+// Deprecated is an optional argument to AddExtracommand, like the deprecated tag.
+func Deprecated(message string) extraCmdInit {
+	return func(c *command) {
+		c.deprecated = true
+		c.deprecation = strings.TrimSpace(message)
+	}
+}
+
+// deprecationNote is like "deprecated: use new-name" in help, or empty if not deprecated.
+func deprecationNote(deprecated bool, deprecation string) string {
+	if !deprecated {
+		return ""
+	}
+	if deprecation == "" {
+		return "deprecated"
+	}
+	return "deprecated: " + deprecation
+}
+
 func longestName(names []string) string {
 	name := ""
 	for _, n := range names {
@@ -0,0 +1,130 @@
+package gli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// ColorMode is when help and error messages are colorized.
+type ColorMode int
+
+const (
+	// ColorNever (default) outputs plain text.
+	ColorNever ColorMode = iota
+	// ColorAuto colorizes if the output is a terminal and NO_COLOR is not set.
+	// On Windows, it also needs a console supporting ANSI escape sequences (virtual terminal processing).
+	ColorAuto
+	// ColorAlways colorizes regardless of the output and NO_COLOR.
+	ColorAlways
+)
+
+// Theme is a palette of ANSI SGR parameters, like "1" (bold) or "1;36" (bold cyan).
+// Empty parameters leave the parts plain.
+type Theme struct {
+	// Header is for section headers, like "Options:".
+	Header string
+	// Command is for command names.
+	Command string
+	// Option is for option names.
+	Option string
+	// Placeholder is for placeholders of options and names of arguments.
+	Placeholder string
+	// Default is for (default: ...) of options.
+	Default string
+	// Error is for error messages written to App.Stderr.
+	Error string
+	// Warning is for warnings written to App.Stderr, like deprecations.
+	Warning string
+}
+
+// DefaultTheme is used if App.Theme is nil.
+var DefaultTheme = Theme{
+	Header:      "1",
+	Command:     "36",
+	Option:      "32",
+	Placeholder: "33",
+	Default:     "2",
+	Error:       "1;31",
+	Warning:     "33",
+}
+
+// helpFormat is how help messages are written.
+type helpFormat struct {
+	// width to wrap in (0: no wrapping)
+	width int
+	// theme is empty if not colorized
+	theme Theme
+	// all includes hidden options and commands (help --all)
+	all bool
+}
+
+// helpFormat returns the format of help messages written to w.
+func (g App) helpFormat(w io.Writer) helpFormat {
+	return helpFormat{
+		width: g.helpWidth(),
+		theme: g.themeFor(w),
+	}
+}
+
+// themeFor returns the theme for w, or an empty Theme if not colorized.
+func (g App) themeFor(w io.Writer) Theme {
+	switch g.Color {
+	case ColorAlways:
+	case ColorAuto:
+		if os.Getenv("NO_COLOR") != "" || !isTerminal(w) || !enableVirtualTerminal(w) {
+			return Theme{}
+		}
+	default:
+		return Theme{}
+	}
+
+	if g.Theme != nil {
+		return *g.Theme
+	}
+	return DefaultTheme
+}
+
+// isTerminal reports whether w is a character device (a terminal).
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok || f == nil {
+		return false
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// paint wraps s in an SGR sequence.
+func paint(sgr, s string) string {
+	if sgr == "" || s == "" {
+		return s
+	}
+	return "\x1b[" + sgr + "m" + s + "\x1b[0m"
+}
+
+var sgrPattern = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// displayWidth is runewidth.StringWidth ignoring SGR sequences.
+func displayWidth(s string) int {
+	if !strings.Contains(s, "\x1b") {
+		return runewidth.StringWidth(s)
+	}
+	return runewidth.StringWidth(sgrPattern.ReplaceAllString(s, ""))
+}
+
+// errorf writes an error message to Stderr, colorized by Theme.Error.
+// Trailing newlines are left plain.
+func (g App) errorf(format string, a ...interface{}) {
+	msg := fmt.Sprintf(format, a...)
+	body := strings.TrimRight(msg, "\n")
+	_, _ = io.WriteString(g.Stderr, paint(g.themeFor(g.Stderr).Error, body)+msg[len(body):])
+}
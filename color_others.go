@@ -0,0 +1,10 @@
+//go:build !windows
+
+package gli
+
+import "io"
+
+// enableVirtualTerminal reports true, since terminals other than Windows consoles handle ANSI escape sequences.
+func enableVirtualTerminal(w io.Writer) bool {
+	return true
+}
@@ -0,0 +1,526 @@
+package gli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Completion writes a static completion script for shell to w.
+//
+// shell is one of "bash", "zsh" and "fish".
+//
+// The script is also available as a hidden sub command:
+//
+//	app completion bash > /etc/bash_completion.d/app
+//	app completion zsh > "${fpath[1]}/_app"
+//	app completion fish > ~/.config/fish/completions/app.fish
+func (g App) Completion(w io.Writer, shell string) error {
+	if g.root == nil {
+		panic("need Bind or use NewWith")
+	}
+
+	nodes := g.completionNodes()
+
+	switch shell {
+	case "bash":
+		writeBashCompletion(w, g.Name, nodes)
+	case "zsh":
+		writeZshCompletion(w, g.Name, nodes)
+	case "fish":
+		writeFishCompletion(w, g.Name, nodes)
+	default:
+		return errors.Wrap(ErrNotDefined, "shell "+shell)
+	}
+
+	return nil
+}
+
+// complNode is a flattened command for script generation.
+type complNode struct {
+	path       string   // longest names joined by a space. root is ""
+	parentPath string   // path of the parent command
+	names      []string // names of the command itself
+	dynamic    bool     // has a Complete hook
+
+	subs []complWord
+	opts []complOpt
+}
+
+type complWord struct {
+	word string
+	help string
+}
+
+type complOpt struct {
+	names   []string // with hyphens
+	help    string
+	withArg bool
+	choices []string
+}
+
+func (g App) completionNodes() []complNode {
+	var nodes []complNode
+
+	g.root.walkVisible(func(c *command) {
+		n := complNode{
+			path:    strings.Join(c.longestNameStack(), " "),
+			names:   c.names,
+			dynamic: c.hasHook("Complete"),
+		}
+		if c.parent != nil {
+			n.parentPath = strings.Join(c.parent.longestNameStack(), " ")
+		}
+
+		for _, s := range c.visibleSubs(false) {
+			for _, name := range s.names {
+				n.subs = append(n.subs, complWord{word: name, help: s.help})
+			}
+		}
+		if c == g.root {
+			n.subs = append(n.subs,
+				complWord{word: "help", help: "show help"},
+				complWord{word: "version", help: "show version"},
+			)
+		}
+
+		for _, o := range c.visibleOptions(false) {
+			co := complOpt{
+				help:    o.help,
+				withArg: o.takesArg(),
+			}
+			for _, name := range o.names {
+				co.names = append(co.names, hyphenate(name))
+			}
+			if choices, ok := o.tag.Lookup("choices"); ok {
+				for _, ch := range strings.Split(choices, ",") {
+					co.choices = append(co.choices, strings.TrimSpace(ch))
+				}
+			}
+			n.opts = append(n.opts, co)
+
+			if c.autoNoBoolOptions && !o.takesArg() {
+				if b, err := strconv.ParseBool(o.defValue); err == nil && b {
+					n.opts = append(n.opts, complOpt{
+						names: []string{"--no-" + o.longestName()},
+						help:  o.help,
+					})
+				}
+			}
+		}
+
+		nodes = append(nodes, n)
+	})
+
+	return nodes
+}
+
+// hyphenate returns "-n" for a single letter name, otherwise "--name".
+func hyphenate(name string) string {
+	if len(name) == 1 {
+		return "-" + name
+	}
+	return "--" + name
+}
+
+// completionIdent makes name usable as a part of shell function names.
+func completionIdent(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+// singleQuote quotes s for bash and zsh.
+func singleQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// fishQuote quotes s for fish.
+func fishQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "'", `\'`)
+	return "'" + s + "'"
+}
+
+func writeCmdPathCases(w io.Writer, nodes []complNode, indent string) {
+	for _, n := range nodes {
+		if n.path == "" {
+			continue
+		}
+		var pats []string
+		for _, name := range n.names {
+			pats = append(pats, strconv.Quote(n.parentPath+":"+name))
+		}
+		fmt.Fprintf(w, "%s%s) cmdpath=%s ;;\n", indent, strings.Join(pats, "|"), strconv.Quote(n.path))
+	}
+}
+
+func writeBashCompletion(w io.Writer, name string, nodes []complNode) {
+	fn := "_" + completionIdent(name)
+
+	fmt.Fprintf(w, "# bash completion for %s\n\n", name)
+	fmt.Fprintf(w, "%s() {\n", fn)
+	fmt.Fprintln(w, `    local cur prev cmdpath="" i`)
+	fmt.Fprintln(w, `    cur="${COMP_WORDS[COMP_CWORD]}"`)
+	fmt.Fprintln(w, `    prev="${COMP_WORDS[COMP_CWORD-1]}"`)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `    for ((i = 1; i < COMP_CWORD; i++)); do`)
+	fmt.Fprintln(w, `        case "${cmdpath}:${COMP_WORDS[i]}" in`)
+	writeCmdPathCases(w, nodes, "            ")
+	fmt.Fprintln(w, `        esac`)
+	fmt.Fprintln(w, `    done`)
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, `    local cmds="" opts="" dyn=""`)
+	fmt.Fprintln(w, `    case "${cmdpath}" in`)
+	for _, n := range nodes {
+		var cmds, opts []string
+		for _, s := range n.subs {
+			cmds = append(cmds, s.word)
+		}
+		for _, o := range n.opts {
+			opts = append(opts, o.names...)
+		}
+		fmt.Fprintf(w, "        %s)\n", strconv.Quote(n.path))
+		fmt.Fprintf(w, "            cmds=%s\n", singleQuote(strings.Join(cmds, " ")))
+		fmt.Fprintf(w, "            opts=%s\n", singleQuote(strings.Join(opts, " ")))
+		if n.dynamic {
+			fmt.Fprintln(w, `            dyn=1`)
+		}
+		fmt.Fprintln(w, `            ;;`)
+	}
+	fmt.Fprintln(w, `    esac`)
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, `    if [[ -n "${dyn}" ]]; then`)
+	fmt.Fprintln(w, `        local IFS=$'\n'`)
+	fmt.Fprintf(w, "        COMPREPLY=( $(\"${COMP_WORDS[0]}\" %s \"${COMP_WORDS[@]:1:COMP_CWORD}\" 2>/dev/null) )\n", completeCommandName)
+	fmt.Fprintln(w, `        return`)
+	fmt.Fprintln(w, `    fi`)
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, `    case "${cmdpath}:${prev}" in`)
+	for _, n := range nodes {
+		for _, o := range n.opts {
+			if !o.withArg {
+				continue
+			}
+			var pats []string
+			for _, oname := range o.names {
+				pats = append(pats, strconv.Quote(n.path+":"+oname))
+			}
+			if len(o.choices) > 0 {
+				fmt.Fprintf(w, "        %s)\n", strings.Join(pats, "|"))
+				fmt.Fprintf(w, "            COMPREPLY=( $(compgen -W %s -- \"${cur}\") )\n", singleQuote(strings.Join(o.choices, " ")))
+				fmt.Fprintln(w, `            return ;;`)
+			} else {
+				fmt.Fprintf(w, "        %s)\n", strings.Join(pats, "|"))
+				fmt.Fprintln(w, `            COMPREPLY=()`)
+				fmt.Fprintln(w, `            return ;;`)
+			}
+		}
+	}
+	fmt.Fprintln(w, `    esac`)
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, `    if [[ "${cur}" == -* ]]; then`)
+	fmt.Fprintln(w, `        COMPREPLY=( $(compgen -W "${opts}" -- "${cur}") )`)
+	fmt.Fprintln(w, `    else`)
+	fmt.Fprintln(w, `        COMPREPLY=( $(compgen -W "${cmds}" -- "${cur}") )`)
+	fmt.Fprintln(w, `    fi`)
+	fmt.Fprintln(w, `}`)
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "complete -o default -F %s %s\n", fn, name)
+}
+
+func writeZshCompletion(w io.Writer, name string, nodes []complNode) {
+	fn := "_" + completionIdent(name)
+
+	fmt.Fprintf(w, "#compdef %s\n\n", name)
+	fmt.Fprintf(w, "%s() {\n", fn)
+	fmt.Fprintln(w, `    local cmdpath="" i dyn=0`)
+	fmt.Fprintln(w, `    local -a cmds opts`)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `    for ((i = 2; i < CURRENT; i++)); do`)
+	fmt.Fprintln(w, `        case "${cmdpath}:${words[i]}" in`)
+	writeCmdPathCases(w, nodes, "            ")
+	fmt.Fprintln(w, `        esac`)
+	fmt.Fprintln(w, `    done`)
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, `    case "${cmdpath}" in`)
+	for _, n := range nodes {
+		var cmds, opts []string
+		for _, s := range n.subs {
+			cmds = append(cmds, singleQuote(s.word+":"+s.help))
+		}
+		for _, o := range n.opts {
+			for _, oname := range o.names {
+				opts = append(opts, singleQuote(oname+":"+o.help))
+			}
+		}
+		fmt.Fprintf(w, "        %s)\n", strconv.Quote(n.path))
+		fmt.Fprintf(w, "            cmds=(%s)\n", strings.Join(cmds, " "))
+		fmt.Fprintf(w, "            opts=(%s)\n", strings.Join(opts, " "))
+		if n.dynamic {
+			fmt.Fprintln(w, `            dyn=1`)
+		}
+		fmt.Fprintln(w, `            ;;`)
+	}
+	fmt.Fprintln(w, `    esac`)
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, `    if (( dyn )); then`)
+	fmt.Fprintln(w, `        local -a cands`)
+	fmt.Fprintf(w, "        cands=( ${(f)\"$(\"${words[1]}\" %s \"${(@)words[2,CURRENT]}\" 2>/dev/null)\"} )\n", completeCommandName)
+	fmt.Fprintln(w, `        compadd -- "${cands[@]}"`)
+	fmt.Fprintln(w, `        return`)
+	fmt.Fprintln(w, `    fi`)
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, `    case "${cmdpath}:${words[CURRENT-1]}" in`)
+	for _, n := range nodes {
+		for _, o := range n.opts {
+			if !o.withArg {
+				continue
+			}
+			var pats []string
+			for _, oname := range o.names {
+				pats = append(pats, strconv.Quote(n.path+":"+oname))
+			}
+			fmt.Fprintf(w, "        %s)\n", strings.Join(pats, "|"))
+			if len(o.choices) > 0 {
+				var quoted []string
+				for _, ch := range o.choices {
+					quoted = append(quoted, singleQuote(ch))
+				}
+				fmt.Fprintf(w, "            compadd -- %s\n", strings.Join(quoted, " "))
+			} else {
+				fmt.Fprintln(w, `            _files`)
+			}
+			fmt.Fprintln(w, `            return ;;`)
+		}
+	}
+	fmt.Fprintln(w, `    esac`)
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, `    if [[ "${words[CURRENT]}" == -* ]]; then`)
+	fmt.Fprintln(w, `        _describe -t options 'option' opts`)
+	fmt.Fprintln(w, `    elif (( ${#cmds} )); then`)
+	fmt.Fprintln(w, `        _describe -t commands 'command' cmds`)
+	fmt.Fprintln(w, `    else`)
+	fmt.Fprintln(w, `        _files`)
+	fmt.Fprintln(w, `    fi`)
+	fmt.Fprintln(w, `}`)
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "if [[ \"${funcstack[1]}\" == %q ]]; then\n", fn)
+	fmt.Fprintf(w, "    %s \"$@\"\n", fn)
+	fmt.Fprintln(w, `else`)
+	fmt.Fprintf(w, "    compdef %s %s\n", fn, name)
+	fmt.Fprintln(w, `fi`)
+}
+
+func writeFishCompletion(w io.Writer, name string, nodes []complNode) {
+	fn := "__" + completionIdent(name) + "_cmdpath"
+
+	fmt.Fprintf(w, "# fish completion for %s\n\n", name)
+	fmt.Fprintf(w, "function %s\n", fn)
+	fmt.Fprintln(w, `    set -l cmdpath ""`)
+	fmt.Fprintln(w, `    for w in (commandline -opc)[2..-1]`)
+	fmt.Fprintln(w, `        switch "$cmdpath:$w"`)
+	for _, n := range nodes {
+		if n.path == "" {
+			continue
+		}
+		var pats []string
+		for _, cname := range n.names {
+			pats = append(pats, fishQuote(n.parentPath+":"+cname))
+		}
+		fmt.Fprintf(w, "            case %s\n", strings.Join(pats, " "))
+		fmt.Fprintf(w, "                set cmdpath %s\n", fishQuote(n.path))
+	}
+	fmt.Fprintln(w, `        end`)
+	fmt.Fprintln(w, `    end`)
+	fmt.Fprintln(w, `    echo $cmdpath`)
+	fmt.Fprintln(w, `end`)
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "function %s_is\n", fn)
+	fmt.Fprintf(w, "    set -l p (%s)\n", fn)
+	fmt.Fprintln(w, `    test "$p" = "$argv[1]"`)
+	fmt.Fprintln(w, `end`)
+	fmt.Fprintln(w)
+
+	for _, n := range nodes {
+		cond := fishQuote(fn + "_is " + strconv.Quote(n.path))
+
+		if n.dynamic {
+			fmt.Fprintf(w, "complete -c %s -f -n %s -a %s\n", name, cond, fishQuote("("+name+" "+completeCommandName+" (commandline -opc)[2..-1] (commandline -ct))"))
+			continue
+		}
+
+		for _, s := range n.subs {
+			fmt.Fprintf(w, "complete -c %s -f -n %s -a %s -d %s\n", name, cond, fishQuote(s.word), fishQuote(s.help))
+		}
+		for _, o := range n.opts {
+			var flags []string
+			for _, oname := range o.names {
+				if strings.HasPrefix(oname, "--") {
+					flags = append(flags, "-l "+oname[2:])
+				} else {
+					flags = append(flags, "-s "+oname[1:])
+				}
+			}
+			if len(o.choices) > 0 {
+				flags = append(flags, "-x -a "+fishQuote(strings.Join(o.choices, " ")))
+			} else if o.withArg {
+				flags = append(flags, "-r")
+			}
+			fmt.Fprintf(w, "complete -c %s -n %s %s -d %s\n", name, cond, strings.Join(flags, " "), fishQuote(o.help))
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// completeCommandName is a hidden entry point for dynamic completion.
+//
+//	a.out __complete [args...] WORD
+//
+// prints candidates for WORD, one per line.
+const completeCommandName = "__complete"
+
+// Completing is passed to a Complete hook of the target command.
+//
+// The hook is resolved like Run, and may return candidates (and an error).
+//
+//	func (ls listCmd) Complete(global *globalCmd, args []string, comp gli.Completing) []string {
+//	    // :
+//	}
+//
+// Candidates not beginning with Word are dropped.
+type Completing struct {
+	// Word is the (partial) word under the cursor.
+	Word string
+	// Option is the longest name of the option whose value is completed.
+	// It is empty while completing a positional argument.
+	Option string
+}
+
+type completing struct {
+	args []string
+
+	word   string
+	prefix string // "--opt=" in --opt=WORD
+
+	pending          string // an option that takes WORD as its value
+	pendingTentative bool
+}
+
+func newCompleting(args []string) *completing {
+	comp := &completing{}
+
+	if len(args) == 0 {
+		return comp
+	}
+
+	comp.word = args[len(args)-1]
+	comp.args = args[:len(args)-1]
+
+	if strings.HasPrefix(comp.word, "-") && strings.Contains(comp.word, "=") {
+		// --opt=WORD
+		pos := strings.Index(comp.word, "=")
+		comp.pending = comp.word[:pos]
+		comp.prefix = comp.word[:pos+1]
+		comp.word = comp.word[pos+1:]
+	} else if n := len(comp.args); n >= 2 && comp.args[n-1] == "=" && strings.HasPrefix(comp.args[n-2], "-") {
+		// --opt = WORD (bash splits words by =)
+		comp.pending = comp.args[n-2]
+		comp.args = comp.args[:n-2]
+	} else if n := len(comp.args); n >= 1 && strings.HasPrefix(comp.args[n-1], "-") && comp.args[n-1] != "--" {
+		// --opt WORD, or --bool WORD
+		comp.pendingTentative = true
+	}
+
+	return comp
+}
+
+// trimPendingOption removes the last option waiting for WORD from args.
+func (comp *completing) trimPendingOption() bool {
+	if !comp.pendingTentative {
+		return false
+	}
+	comp.pendingTentative = false
+
+	comp.pending = comp.args[len(comp.args)-1]
+	comp.args = comp.args[:len(comp.args)-1]
+	return true
+}
+
+func (g *App) outputCompletions(ctx context.Context, comp *completing, cmd *command, cmdStack []*command) error {
+	var cands []string
+	req := Completing{Word: comp.word}
+
+	if comp.pending != "" {
+		o := cmd.findOptionExact(strings.TrimLeft(comp.pending, "-"))
+		if o == nil || !o.takesArg() {
+			return errors.Wrap(ErrNotDefined, "option "+comp.pending)
+		}
+		req.Option = o.longestName()
+
+		if choices, ok := o.tag.Lookup("choices"); ok {
+			for _, ch := range strings.Split(choices, ",") {
+				cands = append(cands, strings.TrimSpace(ch))
+			}
+		}
+
+	} else if strings.HasPrefix(comp.word, "-") {
+		for _, o := range cmd.visibleOptions(false) {
+			for _, name := range o.names {
+				cands = append(cands, hyphenate(name))
+			}
+			if g.AutoNoBoolOptions && !o.takesArg() {
+				cands = append(cands, "--no-"+o.longestName())
+			}
+		}
+
+	} else {
+		for _, s := range cmd.visibleSubs(false) {
+			cands = append(cands, s.names...)
+		}
+		if cmd == g.root {
+			cands = append(cands, "help", "version")
+		}
+	}
+
+	if !strings.HasPrefix(comp.word, "-") || comp.pending != "" {
+		retv, callErr := g.callValues("Complete", cmd.selfV, cmdStack, cmd.args, req, ctx)
+		if callErr == nil {
+			if err := returnErr(retv); err != nil {
+				return err
+			}
+			if len(retv) > 0 {
+				if hookCands, ok := retv[0].Interface().([]string); ok {
+					cands = append(cands, hookCands...)
+				}
+			}
+		}
+	}
+
+	for _, c := range cands {
+		if strings.HasPrefix(c, comp.word) {
+			fmt.Fprintln(g.Stdout, comp.prefix+c)
+		}
+	}
+
+	return nil
+}
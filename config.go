@@ -0,0 +1,347 @@
+package gli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// LoadConfigFile reads a config file and merges its values into the app.
+//
+// The format is decided by the extension of path:
+// ".json" for JSON, ".ini", ".toml" and ".conf" for INI (or TOML-like).
+// Otherwise, the content beginning with { is JSON.
+// See [App.LoadConfig].
+func (g *App) LoadConfigFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var format string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		format = "json"
+	case ".ini", ".toml", ".conf":
+		format = "ini"
+	default:
+		format = "ini"
+		if bytes.HasPrefix(bytes.TrimSpace(content), []byte("{")) {
+			format = "json"
+		}
+	}
+
+	if err := g.LoadConfig(bytes.NewReader(content), format); err != nil {
+		return errors.Wrap(err, path)
+	}
+
+	for _, f := range g.configFiles {
+		if f == path {
+			return nil
+		}
+	}
+	g.configFiles = append(g.configFiles, path)
+
+	return nil
+}
+
+// LoadedConfigFiles returns paths of config files loaded so far, in loading order.
+//
+// Hook functions can take *gli.App to report them.
+//
+//	func (g globalCmd) Run(app *gli.App) {
+//	    fmt.Println(app.LoadedConfigFiles())
+//	}
+func (g App) LoadedConfigFiles() []string {
+	return append([]string(nil), g.configFiles...)
+}
+
+// ConfigSearchPaths returns candidate paths of config files, from lowest to highest precedence.
+//
+// With the app name "app":
+//
+//  1. /etc/app/config.{json,toml,ini} (except Windows)
+//  2. $XDG_CONFIG_HOME/app/config.{json,toml,ini} (see os.UserConfigDir)
+//  3. .apprc in the root directory ... .apprc in the working directory
+//
+// If ConfigDiscovery is true, existing ones are loaded on Run or Parse, before ConfigFile.
+func (g App) ConfigSearchPaths() []string {
+	name := g.Name
+	if strings.EqualFold(filepath.Ext(name), ".exe") {
+		name = name[:len(name)-len(filepath.Ext(name))]
+	}
+	if name == "" {
+		return nil
+	}
+
+	var dirs []string
+	if runtime.GOOS != "windows" {
+		dirs = append(dirs, filepath.Join("/etc", name))
+	}
+	if confdir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(confdir, name))
+	}
+
+	var paths []string
+	for _, d := range dirs {
+		for _, ext := range []string{".json", ".toml", ".ini"} {
+			paths = append(paths, filepath.Join(d, "config"+ext))
+		}
+	}
+
+	if wd, err := os.Getwd(); err == nil {
+		var rcpaths []string
+		for dir := wd; ; dir = filepath.Dir(dir) {
+			rcpaths = append(rcpaths, filepath.Join(dir, "."+name+"rc"))
+			if filepath.Dir(dir) == dir {
+				break
+			}
+		}
+		// farthest first
+		for i := len(rcpaths) - 1; i >= 0; i-- {
+			paths = append(paths, rcpaths[i])
+		}
+	}
+
+	return paths
+}
+
+// loadConfigFiles loads discovered files and ConfigFile, ignoring missing ones.
+func (g *App) loadConfigFiles() error {
+	var paths []string
+	if g.ConfigDiscovery {
+		paths = append(paths, g.ConfigSearchPaths()...)
+	}
+	if g.ConfigFile != "" {
+		paths = append(paths, g.ConfigFile)
+	}
+
+	for _, p := range paths {
+		if err := g.LoadConfigFile(p); err != nil && !os.IsNotExist(errors.Cause(err)) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LoadConfig reads config values from r and merges them into the app.
+// Values loaded later overwrite ones loaded earlier, key by key.
+// So, config files are deeply merged.
+//
+// format is "json", "ini" or "toml".
+//
+// Each value is assigned to an option whose key matches.
+// The key of an option is given by the `config` tag,
+// or derived from the command path and the option name ("sub.subsub.option").
+//
+//	{"file": "./todo.json", "list": {"done": true}}
+//
+//	file = ./todo.json
+//	[list]
+//	done = true
+//
+// Values are decoded in the same way as the default tag.
+// Precedence: default tag < config < env tag < command-line.
+func (g *App) LoadConfig(r io.Reader, format string) error {
+	var values map[string]string
+	var err error
+
+	switch strings.ToLower(format) {
+	case "json":
+		values, err = parseJSONConfig(r)
+	case "ini", "toml":
+		values, err = parseINIConfig(r)
+	default:
+		return errors.Wrap(ErrNotDefined, "config format "+format)
+	}
+	if err != nil {
+		return err
+	}
+
+	if g.config == nil {
+		g.config = make(map[string]string)
+	}
+	for k, v := range values {
+		g.config[k] = v
+	}
+
+	return nil
+}
+
+func parseJSONConfig(r io.Reader) (map[string]string, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	var root map[string]interface{}
+	if err := dec.Decode(&root); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	flattenJSONConfig(values, "", root)
+	return values, nil
+}
+
+func flattenJSONConfig(values map[string]string, prefix string, v interface{}) {
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		flat := prefix != ""
+		var pairs []string
+		for k, e := range vv {
+			flattenJSONConfig(values, configKey(prefix, k), e)
+
+			switch e.(type) {
+			case map[string]interface{}, []interface{}, nil:
+				flat = false
+			}
+			pairs = append(pairs, k+":"+escapeConfigElem(e))
+		}
+		// {"labels": {"a": "1"}} is also labels = "a:1" for map options
+		if flat && len(pairs) > 0 {
+			sort.Strings(pairs)
+			values[prefix] = strings.Join(pairs, ",")
+		}
+
+	case []interface{}:
+		var elems []string
+		for _, e := range vv {
+			elems = append(elems, escapeConfigElem(e))
+		}
+		values[prefix] = strings.Join(elems, ",")
+
+	case nil:
+		// nop
+
+	default:
+		values[prefix] = fmt.Sprint(vv)
+	}
+}
+
+// escapeConfigElem formats an element of a list, escaping commas (\,).
+func escapeConfigElem(e interface{}) string {
+	return strings.ReplaceAll(fmt.Sprint(e), ",", `\,`)
+}
+
+func parseINIConfig(r io.Reader) (map[string]string, error) {
+	values := make(map[string]string)
+	section := ""
+
+	scanner := bufio.NewScanner(r)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+
+		pos := strings.Index(line, "=")
+		if pos == -1 {
+			return nil, fmt.Errorf("line %d: no = in %q", lineno, line)
+		}
+
+		key := unquoteConfigValue(strings.TrimSpace(line[:pos]))
+		value, err := parseINIValue(strings.TrimSpace(line[pos+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineno, err)
+		}
+
+		values[configKey(section, key)] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// parseINIValue handles "quoted", 'literal', [array] and bare values with # comments.
+func parseINIValue(s string) (string, error) {
+	if strings.HasPrefix(s, "[") {
+		end := strings.LastIndex(s, "]")
+		if end == -1 {
+			return "", fmt.Errorf("unterminated array %q", s)
+		}
+
+		var elems []string
+		for _, e := range splitConfigArray(s[1:end]) {
+			e = strings.TrimSpace(e)
+			if e == "" {
+				continue
+			}
+			elems = append(elems, strings.ReplaceAll(unquoteConfigValue(e), ",", `\,`))
+		}
+		return strings.Join(elems, ","), nil
+	}
+
+	if strings.HasPrefix(s, `"`) || strings.HasPrefix(s, "'") {
+		end := strings.LastIndex(s, s[:1])
+		if end == 0 {
+			return "", fmt.Errorf("unterminated string %q", s)
+		}
+		return unquoteConfigValue(s[:end+1]), nil
+	}
+
+	if pos := strings.Index(s, " #"); pos != -1 {
+		s = strings.TrimSpace(s[:pos])
+	}
+	return s, nil
+}
+
+func unquoteConfigValue(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		if u, err := strconv.Unquote(s); err == nil {
+			return u
+		}
+		return s[1 : len(s)-1]
+	}
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// splitConfigArray splits s by commas outside of quotes.
+func splitConfigArray(s string) []string {
+	var result []string
+	var quote rune
+	start := 0
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ',':
+			result = append(result, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(result, s[start:])
+}
+
+// configKey joins a section (or a command path) and a name.
+func configKey(prefix, name string) string {
+	name = strings.ToLower(name)
+	if prefix == "" {
+		return name
+	}
+	return strings.ToLower(prefix) + "." + name
+}
@@ -0,0 +1,170 @@
+package gli
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// WriteJSON writes the whole command/option tree as JSON to w.
+//
+// The same output is available by `app help --json`.
+// (`app help sub --json` writes only the sub command.)
+// Hidden options and commands are included, with "hidden": true.
+//
+//	{
+//	  "name": "app", "desc": "...", "version": "...", "usage": "...", "copyright": "...",
+//	  "command": {
+//	    "name": "", "aliases": [], "help": "", "usage": "",
+//	    "options": [
+//	      {"name": "file", "aliases": ["f"], "placeholder": "FILE", "default": "./todo.json",
+//	       "env": "TODO_FILE", "required": false, "type": "", "goType": "string", "takesArg": true}
+//	    ],
+//	    "commands": [ ... ]
+//	  }
+//	}
+func (g App) WriteJSON(w io.Writer) error {
+	if g.root == nil {
+		panic("need Bind or use NewWith")
+	}
+
+	return writeJSON(w, g.describe())
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+type jsonApp struct {
+	Name      string       `json:"name"`
+	Desc      string       `json:"desc,omitempty"`
+	Version   string       `json:"version,omitempty"`
+	Usage     string       `json:"usage,omitempty"`
+	Copyright string       `json:"copyright,omitempty"`
+	Command   *jsonCommand `json:"command"`
+}
+
+type jsonCommand struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+	Path    []string `json:"path"`
+	Help    string   `json:"help,omitempty"`
+	Usage   string   `json:"usage,omitempty"`
+	Extra   bool     `json:"extra,omitempty"`
+
+	Deprecated  bool   `json:"deprecated,omitempty"`
+	Deprecation string `json:"deprecation,omitempty"`
+	Hidden      bool   `json:"hidden,omitempty"`
+	Category    string `json:"category,omitempty"`
+
+	Arity    *jsonArity     `json:"arity,omitempty"`
+	Options  []*jsonOption  `json:"options,omitempty"`
+	Commands []*jsonCommand `json:"commands,omitempty"`
+}
+
+// jsonArity is the number of arguments. Max < 0 means unlimited.
+type jsonArity struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+type jsonOption struct {
+	Name        string   `json:"name"`
+	Aliases     []string `json:"aliases,omitempty"`
+	Help        string   `json:"help,omitempty"`
+	Placeholder string   `json:"placeholder,omitempty"`
+	Default     string   `json:"default,omitempty"`
+	DefDesc     string   `json:"defdesc,omitempty"`
+	Env         string   `json:"env,omitempty"`
+	Required    bool     `json:"required"`
+	Type        string   `json:"type,omitempty"`
+	GoType      string   `json:"goType"`
+	Choices     []string `json:"choices,omitempty"`
+	TakesArg    bool     `json:"takesArg"`
+	Negation    string   `json:"negation,omitempty"`
+	Deprecated  bool     `json:"deprecated,omitempty"`
+	Deprecation string   `json:"deprecation,omitempty"`
+	Hidden      bool     `json:"hidden,omitempty"`
+	Group       string   `json:"group,omitempty"`
+	Count       bool     `json:"count,omitempty"`
+}
+
+func (g App) describe() *jsonApp {
+	return &jsonApp{
+		Name:      g.Name,
+		Desc:      g.Desc,
+		Version:   g.Version,
+		Usage:     g.Usage,
+		Copyright: g.Copyright,
+		Command:   g.describeCommand(g.Root()),
+	}
+}
+
+func (g App) describeCommand(c *CommandInfo) *jsonCommand {
+	jc := &jsonCommand{
+		Name:    c.Name(),
+		Aliases: aliasesOf(c.Names()),
+		Path:    c.Path(),
+		Help:    c.Help(),
+		Usage:   c.Usage(),
+		Extra:   c.IsExtra(),
+	}
+	if jc.Path == nil {
+		jc.Path = []string{}
+	}
+	jc.Deprecation, jc.Deprecated = c.Deprecated()
+	jc.Hidden = c.Hidden()
+	jc.Category = c.Category()
+	if min, max, ok := c.Arity(); ok {
+		jc.Arity = &jsonArity{Min: min, Max: max}
+	}
+
+	for _, o := range c.Options() {
+		jo := &jsonOption{
+			Name:        o.Name(),
+			Aliases:     aliasesOf(o.Names()),
+			Help:        o.Help(),
+			Placeholder: o.Placeholder(),
+			Default:     o.Default(),
+			DefDesc:     o.DefDesc(),
+			Env:         o.Env(),
+			Required:    o.Required(),
+			Type:        o.DecType(),
+			GoType:      o.Type().String(),
+			TakesArg:    o.TakesArg(),
+		}
+		if choices, ok := o.Tag().Lookup("choices"); ok {
+			for _, ch := range strings.Split(choices, ",") {
+				jo.Choices = append(jo.Choices, strings.TrimSpace(ch))
+			}
+		}
+		jo.Deprecation, jo.Deprecated = o.Deprecated()
+		jo.Hidden = o.Hidden()
+		jo.Group = o.Group()
+		jo.Count = o.IsCount()
+		if g.AutoNoBoolOptions && !o.TakesArg() {
+			jo.Negation = "no-" + o.Name()
+		}
+		jc.Options = append(jc.Options, jo)
+	}
+
+	for _, s := range c.Commands() {
+		jc.Commands = append(jc.Commands, g.describeCommand(s))
+	}
+
+	return jc
+}
+
+// aliasesOf returns names except the longest one.
+func aliasesOf(names []string) []string {
+	lname := longestName(names)
+	var aliases []string
+	for _, n := range names {
+		if n != lname {
+			aliases = append(aliases, n)
+		}
+	}
+	return aliases
+}
@@ -0,0 +1,201 @@
+package gli
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/shu-go/cliparser"
+)
+
+// ParseErrorKind is a kind of ParseError.
+type ParseErrorKind int
+
+const (
+	// KindUnknownOption is for an option not defined in the command (and its ancestors).
+	KindUnknownOption ParseErrorKind = iota + 1
+	// KindUnknownCommand is for a sub command not defined.
+	KindUnknownCommand
+	// KindInvalidValue is for a value rejected by validation tags (min, max, ...).
+	KindInvalidValue
+	// KindMissingRequired is for a required option or argument not given.
+	KindMissingRequired
+	// KindDecodeFailure is for a value that can not be decoded (like "abc" for int).
+	KindDecodeFailure
+	// KindArgCount is for a wrong number of arguments.
+	KindArgCount
+	// KindConflict is for options given together against xor or conflicts tags.
+	KindConflict
+	// KindAmbiguous is for an abbreviation matching two or more names (see App.AllowAbbrev).
+	KindAmbiguous
+	// KindMissingValue is for an option given without its value (like "--name" at the end).
+	KindMissingValue
+)
+
+func (k ParseErrorKind) String() string {
+	switch k {
+	case KindUnknownOption:
+		return "unknown option"
+	case KindUnknownCommand:
+		return "unknown command"
+	case KindInvalidValue:
+		return "invalid value"
+	case KindMissingRequired:
+		return "missing required"
+	case KindDecodeFailure:
+		return "decoder failure"
+	case KindArgCount:
+		return "wrong number of arguments"
+	case KindConflict:
+		return "conflict"
+	case KindAmbiguous:
+		return "ambiguous"
+	case KindMissingValue:
+		return "missing value"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseError is returned by Run and Parse when the command line does not fit the struct.
+//
+//	var perr *gli.ParseError
+//	if errors.As(err, &perr) && perr.Kind == gli.KindUnknownCommand {
+//	    fmt.Println("maybe", perr.Suggestions)
+//	}
+//
+// The cause (like ErrNotDefined or a strconv error) is available by errors.Is, errors.As and errors.Cause.
+type ParseError struct {
+	Kind ParseErrorKind
+
+	// Name is the name of the option, the command or the argument.
+	Name string
+	// Path is the command path, like ["sub", "subsub"]. Empty for the root command.
+	Path []string
+	// Index is the index of the offending argument in args of Run or Parse, or -1 if not known.
+	Index int
+	// Suggestions are similar names, for unknown options and commands.
+	// For KindAmbiguous, they are the candidates.
+	Suggestions []string
+
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	if e.Err == nil {
+		return strings.TrimSpace(e.Kind.String() + " " + e.Name)
+	}
+	return e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Cause is for errors.Cause of github.com/pkg/errors.
+func (e *ParseError) Cause() error {
+	return e.Err
+}
+
+func newParseError(kind ParseErrorKind, name string, cmd *command, index int, err error) *ParseError {
+	return &ParseError{
+		Kind:  kind,
+		Name:  name,
+		Path:  cmd.longestNameStack(),
+		Index: index,
+		Err:   err,
+	}
+}
+
+// valueErrorKind is KindInvalidValue for validation errors, otherwise KindDecodeFailure.
+func valueErrorKind(err error) ParseErrorKind {
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		return KindInvalidValue
+	}
+	return KindDecodeFailure
+}
+
+// argvPos is a position in args, and in grouped short options (-abc).
+type argvPos struct {
+	i      int // index of args
+	letter int // index of letters of args[i]
+}
+
+// argvIndex finds c in args from *pos, and moves *pos forward.
+// names are names of the option (c.Name may be an alias resolved by the parser).
+// It returns -1 if not found.
+func argvIndex(args []string, pos *argvPos, c *cliparser.Component, names []string) int {
+	isName := func(n string) bool {
+		if n == c.Name {
+			return true
+		}
+		for _, nn := range names {
+			if n == nn {
+				return true
+			}
+		}
+		return false
+	}
+	// --opt VALUE
+	skipValue := func(i int) {
+		if c.Arg != "" && i+1 < len(args) && args[i+1] == c.Arg && !strings.Contains(args[i], "=") {
+			pos.i = i + 2
+		}
+	}
+
+	for i := pos.i; i < len(args); i++ {
+		a := args[i]
+
+		switch c.Type {
+		case cliparser.Option:
+			if !strings.HasPrefix(a, "-") || a == "-" || a == "--" {
+				continue
+			}
+			name := strings.SplitN(strings.TrimLeft(a, "-"), "=", 2)[0]
+
+			if isName(name) {
+				pos.i, pos.letter = i+1, 0
+				skipValue(i)
+				return i
+			}
+			if strings.HasPrefix(a, "--") {
+				continue
+			}
+
+			// -abc
+			start := 0
+			if i == pos.i {
+				start = pos.letter
+			}
+			for li := start; li < len(name); li++ {
+				if !isName(name[li : li+1]) {
+					continue
+				}
+
+				if li == len(name)-1 {
+					pos.i, pos.letter = i+1, 0
+					skipValue(i)
+				} else {
+					pos.i, pos.letter = i, li+1
+				}
+				return i
+			}
+
+		case cliparser.Command:
+			if a != c.Name {
+				continue
+			}
+			pos.i, pos.letter = i+1, 0
+			return i
+
+		default:
+			if a != c.Arg {
+				continue
+			}
+			pos.i, pos.letter = i+1, 0
+			return i
+		}
+	}
+
+	return -1
+}
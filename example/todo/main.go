@@ -31,8 +31,8 @@ type common struct {
 }
 
 type listCmd struct {
-	Done   bool `cli:"done" help:"display only done items"`
-	Undone bool `cli:"undone,un,u" help:"display only undone items"`
+	Done   bool `cli:"done" help:"display only done items" xor:"state"`
+	Undone bool `cli:"undone,un,u" help:"display only undone items" xor:"state"`
 
 	common
 }
@@ -72,10 +72,6 @@ func (ls listCmd) Run(global *globalCmd, args []string) error {
 	if ls.DummyDefault != "by struct common" {
 		return errors.New("--dd is not \"by struct common\"")
 	}
-	if ls.Done && ls.Undone {
-		//fmt.Println("--done and --undone is exclusive")
-		return fmt.Errorf("--done and --undone is exclusive")
-	}
 
 	list := todoList{}
 	if err := list.Load(global.File); err != nil {
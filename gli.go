@@ -13,14 +13,16 @@ package gli
 
 import (
 	//"errors"
+	"context"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"reflect"
-	"regexp"
 	"strconv"
 	"strings"
+	"syscall"
 
 	"github.com/pkg/errors"
 	"github.com/shu-go/cliparser"
@@ -72,6 +74,45 @@ type App struct {
 	RequiredTag string
 	// DecTypeTag is a tag key that is used to lookup decoder. default: `type`
 	DecTypeTag string
+	// ConfigTag is a tag key. default: `config`
+	ConfigTag string
+	// ArgTag is a tag key for a positional argument. default: `arg`
+	ArgTag string
+	// ArgsTag is a tag key for the rest of positional arguments. default: `args`
+	ArgsTag string
+	// XorTag is a tag key for mutually exclusive options. default: `xor`
+	XorTag string
+	// RequiresTag is a tag key for options required together. default: `requires`
+	RequiresTag string
+	// RequiresAnyTag is a tag key for options at least one of which is required. default: `requiresany`
+	RequiresAnyTag string
+	// ConflictsTag is a tag key for conflicting options. default: `conflicts`
+	ConflictsTag string
+	// DeprecatedTag is a tag key for deprecated options and commands. default: `deprecated`
+	DeprecatedTag string
+	// HiddenTag is a tag key for options and commands not shown in help, completions and documents. default: `hidden`
+	HiddenTag string
+	// GroupTag is a tag key for a section of options in help. default: `group`
+	GroupTag string
+	// CategoryTag is a tag key for a section of sub commands in help. default: `category`
+	CategoryTag string
+	// MapToTag is a tag key for an option to set another field (named by the tag value). default: `mapto`
+	MapToTag string
+	// MinTag is a tag key for the min of a number or a length. default: `min`
+	MinTag string
+	// MaxTag is a tag key for the max of a number or a length. default: `max`
+	MaxTag string
+	// LenTag is a tag key for the length (N or N..M). default: `len`
+	LenTag string
+	// PatternTag is a tag key for a regexp values must match. default: `pattern`
+	PatternTag string
+	// OneOfTag is a tag key for allowed values (comma separated). default: `oneof`
+	OneOfTag string
+
+	// ConfigFile is loaded on Run or Parse, if exists. See LoadConfig.
+	ConfigFile string
+	// ConfigDiscovery enables loading config files in ConfigSearchPaths.
+	ConfigDiscovery bool
 
 	// MyCommandABC => false(default): "mycommandabc" , true: "my-command-abc"
 	HyphenedCommandName bool
@@ -81,6 +122,34 @@ type App struct {
 	OptionsGrouped bool
 	DoubleHyphen   bool
 
+	// HelpWidth is the width to wrap help messages in.
+	// 0 (default): $COLUMNS if set, otherwise no wrapping. Negative: no wrapping.
+	HelpWidth int
+
+	// HelpTemplate is a text/template for help messages, instead of the built-in layout.
+	// See HelpData for the data model.
+	HelpTemplate string
+
+	// AllowAbbrev accepts unique prefixes of sub command names and long option names.
+	// (`li` for `list`, `--verb` for `--verbose`)
+	AllowAbbrev bool
+
+	// SuggestDistance is the max edit distance of suggestions for unknown options and commands.
+	// 0 disables suggestions. default: 2
+	SuggestDistance int
+
+	// StrictCommands makes an arg an unknown command (ParseError of KindUnknownCommand with Suggestions),
+	// if the command has sub commands and takes no args (no Run, arg, args).
+	// false (default): help is shown with suggestions, and Run returns nil.
+	StrictCommands bool
+
+	// Color is when help and error messages are colorized by Theme. default: ColorNever
+	//
+	// ColorAuto colorizes only if the output is a terminal and NO_COLOR is not set.
+	Color ColorMode
+	// Theme is the palette of colors. nil: DefaultTheme
+	Theme *Theme
+
 	// SuppressErrorOutput is an option to suppresses on cli parsing error.
 	SuppressErrorOutput bool
 	Stdout, Stderr      *os.File
@@ -97,6 +166,9 @@ type App struct {
 
 	parser cliparser.Parser
 	root   *command
+
+	config      map[string]string
+	configFiles []string
 }
 
 // New makes main gli instance to parse and invoke hooks.
@@ -116,12 +188,33 @@ func New() App {
 		EnvTag:      "env",
 		RequiredTag: "required",
 		DecTypeTag:  "type",
+		ConfigTag:   "config",
+		ArgTag:      "arg",
+		ArgsTag:     "args",
+		XorTag:      "xor",
+
+		RequiresTag:    "requires",
+		RequiresAnyTag: "requiresany",
+		ConflictsTag:   "conflicts",
+
+		DeprecatedTag: "deprecated",
+		HiddenTag:     "hidden",
+		GroupTag:      "group",
+		CategoryTag:   "category",
+		MapToTag:      "mapto",
+
+		MinTag:     "min",
+		MaxTag:     "max",
+		LenTag:     "len",
+		PatternTag: "pattern",
+		OneOfTag:   "oneof",
 
 		HyphenedCommandName: false,
 		HyphenedOptionName:  false,
 		OptionsGrouped:      true,
 		AutoNoBoolOptions:   true,
 		DoubleHyphen:        true,
+		SuggestDistance:     2,
 
 		Stdout: os.Stdout,
 		Stderr: os.Stderr,
@@ -130,6 +223,7 @@ func New() App {
 	//HINT
 	app.parser.HintCommand("help")
 	app.parser.HintCommand("version")
+	app.parser.HintCommand("completion")
 	app.parser.HintLongName("help")
 	app.parser.HintLongName("version")
 
@@ -169,7 +263,10 @@ func (g *App) Bind(ptrSt interface{}) error {
 		autoNoBoolOptions: g.AutoNoBoolOptions,
 	}
 
-	return g.scanMeta(v.Type(), g.root)
+	if err := g.scanMeta(v.Type(), g.root); err != nil {
+		return err
+	}
+	return g.checkRuleNames(g.root)
 }
 
 func (g *App) scanMeta(t reflect.Type, cmd *command) error {
@@ -179,8 +276,11 @@ func (g *App) scanMeta(t reflect.Type, cmd *command) error {
 	if t.Kind() != reflect.Struct {
 		return nil
 	}
+	cmd.selfT = t
 
 	fields := fieldsOf(t)
+	// by field paths
+	firstParsings := make(map[string]*bool)
 
 	for i := 0; i < len(fields); i++ {
 		ft := fields[i].Field
@@ -198,6 +298,18 @@ func (g *App) scanMeta(t reflect.Type, cmd *command) error {
 				if tv, ok := tag.Lookup(g.UsageTag); ok && cmd.usage == "" {
 					cmd.usage = strings.TrimSpace(tv)
 				}
+				// at least one of options
+				if tv, ok := tag.Lookup(g.RequiresAnyTag); ok && cmd.requiresAny == nil {
+					cmd.requiresAny = splitTagList(tv)
+				}
+				// number of args
+				if tv, ok := tag.Lookup(g.ArgsTag); ok && cmd.arity == nil {
+					a, err := parseArity(tv)
+					if err != nil {
+						return err
+					}
+					cmd.arity = a
+				}
 			}
 
 			continue
@@ -205,6 +317,23 @@ func (g *App) scanMeta(t reflect.Type, cmd *command) error {
 
 		tag := ft.Tag
 
+		// positional args
+		if a, isarg, err := g.parseArgTag(ft, fields[i].Path); err != nil {
+			return err
+		} else if isarg {
+			for _, b := range cmd.posArgs {
+				if a.rest && b.rest {
+					return fmt.Errorf("args %s: the rest is already bound to %s", ft.Name, b.name)
+				}
+				if !a.rest && !b.rest && a.index == b.index {
+					return fmt.Errorf("arg %s: position %d is already bound to %s", ft.Name, a.index, b.name)
+				}
+			}
+			cmd.posArgs = append(cmd.posArgs, a)
+			cmd.sortArgs()
+			continue
+		}
+
 		var dectype string
 		dectype = strings.TrimSpace(tag.Get(g.DecTypeTag))
 
@@ -265,13 +394,36 @@ func (g *App) scanMeta(t reflect.Type, cmd *command) error {
 		}
 		help = strings.TrimSpace(tag.Get(g.HelpTag))
 		usage = strings.TrimSpace(tag.Get(g.UsageTag))
+		configkey := strings.TrimSpace(tag.Get(g.ConfigTag))
+		xor := splitTagList(tag.Get(g.XorTag))
+		deprecation, deprecated := tag.Lookup(g.DeprecatedTag)
+		deprecation = strings.TrimSpace(deprecation)
+		hidden, err := strconv.ParseBool(strings.TrimSpace(tag.Get(g.HiddenTag)))
+		if err != nil {
+			hidden = false
+		}
 
 		if iscmd /* f.Kind() == reflect.Struct */ {
+			var subarity *arity
+			if tv, ok := tag.Lookup(g.ArgsTag); ok {
+				subarity, err = parseArity(tv)
+				if err != nil {
+					return err
+				}
+			}
+
 			sub := &command{
 				names:             names,
 				help:              help,
 				usage:             usage,
+				tag:               tag,
 				fieldIdx:          i,
+				fieldPath:         fields[i].Path,
+				arity:             subarity,
+				deprecated:        deprecated,
+				deprecation:       deprecation,
+				hidden:            hidden,
+				category:          strings.TrimSpace(tag.Get(g.CategoryTag)),
 				parent:            cmd,
 				autoNoBoolOptions: g.AutoNoBoolOptions,
 			}
@@ -299,17 +451,61 @@ func (g *App) scanMeta(t reflect.Type, cmd *command) error {
 			}
 		} else {
 			opt := &option{
-				names:              names,
-				env:                env,
-				defValue:           defvalue,
-				defDesc:            defdesc,
-				required:           required,
-				dectype:            dectype,
-				help:               help,
-				tag:                tag,
-				placeholder:        placeholder,
-				fieldIdx:           fields[i].Path,
-				nondefFirstParsing: true,
+				names:       names,
+				env:         env,
+				defValue:    defvalue,
+				defDesc:     defdesc,
+				required:    required,
+				dectype:     dectype,
+				help:        help,
+				tag:         tag,
+				placeholder: placeholder,
+				xor:         xor,
+				requires:    splitTagList(tag.Get(g.RequiresTag)),
+				requiresAny: splitTagList(tag.Get(g.RequiresAnyTag)),
+				conflicts:   splitTagList(tag.Get(g.ConflictsTag)),
+				deprecated:  deprecated,
+				deprecation: deprecation,
+				hidden:      hidden,
+				group:       strings.TrimSpace(tag.Get(g.GroupTag)),
+				fieldIdx:    fields[i].Path,
+				typ:         ft.Type,
+			}
+			if tv := strings.TrimSpace(tag.Get(g.MapToTag)); tv != "" {
+				// OldName string `cli:"old-name" deprecated:"use --new-name" mapto:"NewName"`
+				target, found := fieldByName(fields, tv)
+				if !found {
+					return errors.Wrapf(ErrNotDefined, "field %s (mapto of %s)", tv, ft.Name)
+				}
+				opt.fieldIdx = target.Path
+				opt.typ = target.Field.Type
+				isbool = opt.typ.Kind() == reflect.Bool
+			}
+			fpkey := fmt.Sprint(opt.fieldIdx)
+			if firstParsings[fpkey] == nil {
+				first := true
+				firstParsings[fpkey] = &first
+			}
+			opt.nondefFirstParsing = firstParsings[fpkey]
+			if opt.typ == reflect.TypeOf(Count(0)) || dectype == "Count" {
+				switch opt.typ.Kind() {
+				case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				default:
+					return fmt.Errorf("option %s: Count requires an integer field", opt.longestName())
+				}
+				// -vvv
+				opt.counter = true
+			}
+			if opt.validation, err = g.parseValidation(opt.typ, tag); err != nil {
+				return errors.Wrapf(err, "field %s", ft.Name)
+			}
+			switch configkey {
+			case "-":
+				// not configurable
+			case "":
+				opt.configKey = configKey(strings.Join(cmd.longestNameStack(), "."), opt.longestName())
+			default:
+				opt.configKey = configKey("", configkey)
 			}
 			cmd.options = append(cmd.options, opt)
 
@@ -319,7 +515,7 @@ func (g *App) scanMeta(t reflect.Type, cmd *command) error {
 				if len(names[ni]) > 1 {
 					g.parser.HintLongName(names[ni], cmd.longestNameStack())
 				}
-				if !isbool {
+				if !isbool && !opt.counter {
 					g.parser.HintWithArg(names[ni], cmd.longestNameStack())
 				}
 				g.parser.HintAlias(names[ni], lname)
@@ -367,6 +563,9 @@ func (g *App) AddExtraCommand(ptrSt interface{}, names, help string, inits ...ex
 	}
 
 	err := g.scanMeta(v.Type(), &cmd)
+	if err == nil {
+		err = g.checkRuleNames(&cmd)
+	}
 	if err != nil {
 		panic(err.Error())
 	}
@@ -383,7 +582,7 @@ func (g *App) Parse(args []string) (tgt interface{}, tgtargs []string, err error
 		panic("need Bind or use NewWith")
 	}
 
-	return g.exec(args, false)
+	return g.exec(context.Background(), args, false)
 }
 
 // Run parses args and calls Run method of a subcommand.
@@ -392,11 +591,31 @@ func (g *App) Run(args []string) error {
 		panic("need Bind or use NewWith")
 	}
 
-	_, _, err := g.exec(args, true)
+	_, _, err := g.exec(context.Background(), args, true)
 	return err
 }
 
-func (g *App) exec(args []string, doRun bool) (tgt interface{}, tgtargs []string, appRunErr error) {
+// RunContext is Run with ctx, which is passed to hook functions taking context.Context.
+//
+//	func (g *globalCmd) Run(ctx context.Context, args []string) error {
+//	    // :
+//	}
+//
+// ctx is canceled on SIGINT or SIGTERM,
+// so that Run can stop its work and After hooks are called.
+func (g *App) RunContext(ctx context.Context, args []string) error {
+	if g.root == nil {
+		panic("need Bind or use NewWith")
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	_, _, err := g.exec(ctx, args, true)
+	return err
+}
+
+func (g *App) exec(ctx context.Context, args []string, doRun bool) (tgt interface{}, tgtargs []string, appRunErr error) {
 	cmd := g.root
 
 	if !g.DoubleHyphen {
@@ -408,48 +627,127 @@ func (g *App) exec(args []string, doRun bool) (tgt interface{}, tgtargs []string
 		copy(args, os.Args[1:])
 	}
 
+	// hidden: a.out __complete [args...] WORD
+	var comp *completing
+	if len(args) > 0 && args[0] == completeCommandName {
+		if sub, _ := cmd.findCommandExact(completeCommandName); sub == nil {
+			comp = newCompleting(args[1:])
+			args = comp.args
+
+			suppress := g.SuppressErrorOutput
+			g.SuppressErrorOutput = true
+			defer func() { g.SuppressErrorOutput = suppress }()
+		}
+	}
+
+	if err := g.loadConfigFiles(); err != nil {
+		if !g.SuppressErrorOutput {
+			g.errorf("%v\n", err)
+		}
+		return nil, nil, err
+	}
+
 	cmdStack := []*command{cmd}
 	cmd.setMembersReferMe()
-	cmd.setDefaultValues(g.DecTypeTag)
-
-	_, defErr := g.call("Init", cmd.selfV, cmdStack, cmd.args)
+	// errors of default, config and env values are reported after help, version and completion
+	valueErr := cmd.setDefaultValues(g.DecTypeTag, g.config)
+	_, defErr := g.call("Init", cmd.selfV, cmdStack, cmd.args, ctx)
 	if defErr != nil {
 		if !g.SuppressErrorOutput {
-			fmt.Fprintf(g.Stderr, "%v\n", defErr)
+			g.errorf("%v\n", defErr)
 		}
 		return nil, nil, defErr
 	}
 
+	var counts map[int]string
+	if comp == nil {
+		var err error
+		args, counts, err = g.preprocessArgs(args)
+		if err != nil {
+			if !g.SuppressErrorOutput {
+				g.errorf("%v\n", err)
+			}
+			return nil, nil, err
+		}
+	}
+
 	helpMode := false
+	jsonMode := false
+	allMode := false
+	warned := make(map[string]bool)
 
 	g.parser.Reset()
 	g.parser.Feed(args)
-	if err := g.parser.Parse(); err != nil {
+	err := g.parser.Parse()
+	if err != nil && comp != nil && comp.trimPendingOption() {
+		// a.out __complete --opt WORD
+		g.parser.Reset()
+		g.parser.Feed(comp.args)
+		err = g.parser.Parse()
+	}
+	if err != nil {
 		if !g.SuppressErrorOutput {
-			fmt.Fprintf(g.Stderr, "%v\n", err)
+			g.errorf("%v\n", err)
 		}
-		return nil, nil, err
+		// mostly reported by preprocessArgs
+		return nil, nil, newParseError(KindDecodeFailure, "", g.root, -1, err)
 	}
 
+	var argPos argvPos
 	for {
 		c := g.parser.GetComponent()
 		if c == nil {
 			break
 		}
+		var optNames []string
+		if c.Type == cliparser.Option {
+			if o := cmd.findOptionExact(c.Name); o != nil {
+				optNames = o.names
+			}
+		}
+		argIdx := argvIndex(args, &argPos, c, optNames)
 
 		if c.Name == "help" {
 			helpMode = true
 			continue
 		}
 
-		if len(cmdStack) == 1 && (c.Name == "version") {
+		// hidden: a.out help --json
+		if helpMode && c.Type == cliparser.Option && c.Name == "json" && cmd.findOptionExact(c.Name) == nil {
+			jsonMode = true
+			continue
+		}
+
+		// a.out help --all: including hidden options and commands
+		if helpMode && c.Type == cliparser.Option && c.Name == "all" && cmd.findOptionExact(c.Name) == nil {
+			allMode = true
+			continue
+		}
+
+		if comp == nil && len(cmdStack) == 1 && (c.Name == "version") {
 			fmt.Fprintln(g.Stdout, g.Version)
 			return nil, nil, nil
 		}
 
+		// hidden: a.out completion bash
+		if comp == nil && len(cmdStack) == 1 && c.Type == cliparser.Command && c.Name == "completion" {
+			if sub, _ := cmd.findCommandExact(c.Name); sub == nil {
+				var shell string
+				if sc := g.parser.GetComponent(); sc != nil {
+					shell = sc.Arg
+				}
+				err := g.Completion(g.Stdout, shell)
+				if err != nil && !g.SuppressErrorOutput {
+					g.errorf("%v\n", err)
+				}
+				return nil, nil, err
+			}
+		}
+
 		switch c.Type {
 		case cliparser.Arg:
 			cmd.args = append(cmd.args, c.Arg)
+			cmd.argIdx = append(cmd.argIdx, argIdx)
 
 		case cliparser.Option:
 			//rog.Debug(c.Name)
@@ -458,66 +756,69 @@ func (g *App) exec(args []string, doRun bool) (tgt interface{}, tgtargs []string
 			// "--no-bool" ?
 			if g.AutoNoBoolOptions && o == nil && strings.HasPrefix(c.Name, "no-") {
 				o = cmd.findOptionExact(c.Name[3:])
-				fv := o.ownerV.Elem().FieldByIndex(o.fieldIdx)
-				if o != nil && fv.Type().Kind() == reflect.Bool {
+				if o != nil && !o.takesArg() {
 					c.Name = c.Name[3:]
 					c.Arg = "false"
+				} else {
+					o = nil
 				}
 			}
 
 			if o == nil {
+				candidates := g.optionSuggestions(c.Name, cmd)
+
 				if !g.SuppressErrorOutput {
-					fmt.Fprintf(g.Stdout, "option %q %v\n\n", c.Name, ErrNotDefined)
-
-					var candidates []string
-					for oi := 0; oi < len(cmd.options); oi++ {
-						names := cmd.options[oi].names
-						for ni := 0; ni < len(names); ni++ {
-							if strings.HasPrefix(names[ni], c.Name) {
-								candidates = append(candidates, names[ni])
-								break
-							} else if re, err := regexp.Compile("[" + names[ni] + "]"); err == nil {
-								if len(re.ReplaceAllLiteralString(c.Name, "")) <= len(c.Name)/10 {
-									candidates = append(candidates, names[ni])
-									break
-								}
-							}
-						}
-					}
+					g.errorf("option %q %v\n\n", c.Name, ErrNotDefined)
 					if len(candidates) > 0 {
-						fmt.Fprintf(g.Stdout, "    maybe %v ?\n\n", candidates)
+						fmt.Fprintf(g.Stderr, "    maybe %v ?\n\n", candidates)
 					}
 					g.Help(g.Stdout)
 				}
-				return nil, nil, errors.Wrap(ErrNotDefined, "option "+c.Name)
+
+				perr := newParseError(KindUnknownOption, c.Name, cmd, argIdx, errors.Wrap(ErrNotDefined, "option "+c.Name))
+				perr.Suggestions = candidates
+				return nil, nil, perr
 			}
 
 			fv := o.ownerV.Elem().FieldByIndex(o.fieldIdx)
-			err := setOptValue(fv, c.Arg, o.tag, g.DecTypeTag, false, &o.nondefFirstParsing)
+			if o.counter {
+				switch v, found := counts[argIdx]; {
+				case found: // --verbose=3
+					c.Arg = v
+				case c.Arg == "false": // --no-verbose
+					c.Arg = "0"
+				case *o.nondefFirstParsing: // the first -v overrides default, config and env
+					c.Arg = "1"
+				default:
+					c.Arg = strconv.FormatInt(fv.Int()+1, 10)
+				}
+			}
+			err := setOptValue(fv, c.Arg, o.tag, o.validation, g.DecTypeTag, false, o.nondefFirstParsing)
 			if err != nil {
 				if !g.SuppressErrorOutput {
-					fmt.Fprintf(g.Stderr, "option %q: %v\n\n", c.Name, err)
+					g.errorf("option %q: %v\n\n", c.Name, err)
 					g.Help(g.Stdout)
 				}
-				return nil, nil, err
+				return nil, nil, newParseError(valueErrorKind(err), o.longestName(), cmd, argIdx, errors.Wrap(err, "option "+o.longestName()))
 			}
 			o.assigned = true
+			o.defaulted = false
+			o.cliGiven = true
+
+			if o.deprecated && !helpMode {
+				g.warnDeprecated(warned, "option "+hyphenate(o.longestName()), o.deprecation)
+			}
 
 		case cliparser.Command: // may be an arg
 			if len(cmd.subs)+len(cmd.extras) == 0 {
 				cmd.args = append(cmd.args, c.Name) // command name? -> no, it's an arg
+				cmd.argIdx = append(cmd.argIdx, argIdx)
 				continue
 			}
 
 			sub, isextra := cmd.findCommandExact(c.Name)
 			if sub == nil {
-				if !g.SuppressErrorOutput {
-					//rog.Debug("notdefined")
-					fmt.Fprintf(g.Stderr, "command %q %v\n\n", c.Name, ErrNotDefined)
-					g.Help(g.Stdout)
-				}
-				//rog.Debug("notdefined")
-				return nil, nil, errors.Wrap(ErrNotDefined, "command "+c.Name)
+				return nil, nil, g.unknownCommand(c.Name, cmd, argIdx)
 			}
 
 			if !isextra {
@@ -532,44 +833,123 @@ func (g *App) exec(args []string, doRun bool) (tgt interface{}, tgtargs []string
 			}
 			cmd = sub
 			cmdStack = append(cmdStack, cmd)
+			if cmd.deprecated && !helpMode {
+				g.warnDeprecated(warned, "command "+cmd.longestName(), cmd.deprecation)
+			}
 			cmd.setMembersReferMe()
-			cmd.setDefaultValues(g.DecTypeTag)
-
-			_, defErr := g.call("Init", cmd.selfV, cmdStack, cmd.args)
+			if err := cmd.setDefaultValues(g.DecTypeTag, g.config); err != nil && valueErr == nil {
+				valueErr = err
+			}
+			_, defErr := g.call("Init", cmd.selfV, cmdStack, cmd.args, ctx)
 			if defErr != nil {
+				if !g.SuppressErrorOutput {
+					g.errorf("%v\n", defErr)
+				}
 				return nil, nil, defErr
 			}
 		}
 	}
 
+	if comp != nil {
+		return nil, nil, g.outputCompletions(ctx, comp, cmd, cmdStack)
+	}
+
+	if jsonMode {
+		var err error
+		if cmd == g.root {
+			err = writeJSON(g.Stdout, g.describe())
+		} else {
+			err = writeJSON(g.Stdout, g.describeCommand(&CommandInfo{cmd: cmd}))
+		}
+		return nil, nil, err
+	}
+
 	if helpMode {
 		funcName := "Help"
 
-		callErr, helpErr := g.call(funcName, cmd.selfV, cmdStack, cmd.args)
+		callErr, helpErr := g.call(funcName, cmd.selfV, cmdStack, cmd.args, ctx)
 		if callErr == ErrNotRunnable {
-			callErr, helpErr = g.call(funcName, g.root.selfV, cmdStack, g.root.args)
+			callErr, helpErr = g.call(funcName, g.root.selfV, cmdStack, g.root.args, ctx)
 		}
 
 		if callErr != nil {
-			if cmd.selfV == g.root.selfV {
-				g.Help(g.Stdout)
-			} else {
-				cmd.outputHelp(g.Stdout)
-			}
+			g.writeHelp(g.Stdout, cmd, allMode)
 		}
 
 		return nil, nil, helpErr
 	}
 
-	err := errorIfEmptyRequired(cmdStack)
+	if valueErr != nil {
+		if !g.SuppressErrorOutput {
+			g.errorf("%v\n", valueErr)
+		}
+		return nil, nil, valueErr
+	}
+
+	// a.out typo: not a command but an arg, and nothing takes it
+	if doRun && len(cmd.subs)+len(cmd.extras) > 0 && len(cmd.args) > 0 &&
+		len(cmd.posArgs) == 0 && cmd.arity == nil && !cmd.hasHook("Run") {
+		if g.StrictCommands {
+			return nil, nil, g.unknownCommand(cmd.args[0], cmd, cmd.argvIndexOf(0))
+		}
+		// help follows (no Run)
+		g.suggestCommands(cmd.args[0], cmd)
+	}
+
+	if cmd.arity != nil {
+		err = cmd.arity.check(len(cmd.args))
+		if err != nil {
+			idx := -1
+			if cmd.arity.max >= 0 {
+				idx = cmd.argvIndexOf(cmd.arity.max)
+			}
+			err = newParseError(KindArgCount, "", cmd, idx, err)
+			if !g.SuppressErrorOutput {
+				g.errorf("%v\n", err)
+				g.helpOf(g.Stdout, cmd)
+			}
+			return nil, nil, err
+		}
+	}
+
+	for _, c := range cmdStack {
+		err = c.setArgValues(g.DecTypeTag)
+		if err != nil {
+			if !g.SuppressErrorOutput {
+				g.errorf("%v\n", err)
+				g.helpOf(g.Stdout, c)
+			}
+			return nil, nil, err
+		}
+	}
+
+	err = errorIfEmptyRequired(cmdStack)
+	if err == nil {
+		err = errorIfLenBroken(cmdStack)
+	}
+	if err == nil {
+		err = errorIfExclusive(cmdStack)
+	}
+	if err == nil {
+		err = g.errorIfRuleBroken(cmdStack)
+	}
 	if err != nil {
 		if !g.SuppressErrorOutput {
-			fmt.Fprintln(g.Stderr, err)
+			g.errorf("%v\n", err)
 			g.Help(g.Stdout)
 		}
 		return nil, nil, err
 	}
 
+	err = callValidators(cmdStack)
+	if err != nil {
+		if !g.SuppressErrorOutput {
+			g.errorf("%v\n", err)
+			g.helpOf(g.Stdout, cmd)
+		}
+		return nil, nil, err
+	}
+
 	// call Before->Run->After
 
 	// Before/After
@@ -578,18 +958,18 @@ func (g *App) exec(args []string, doRun bool) (tgt interface{}, tgtargs []string
 
 	if doRun {
 		for ci := 0; ci < len(cmdStack); ci++ {
-			callErr, beforeErr := g.call("Before", cmdStack[ci].selfV, cmdStack, cmdStack[ci].args)
+			callErr, beforeErr := g.call("Before", cmdStack[ci].selfV, cmdStack, cmdStack[ci].args, ctx)
 			if callErr == nil && beforeErr != nil {
 				if !g.SuppressErrorOutput {
-					fmt.Fprintf(g.Stderr, "%v\n", beforeErr)
-					cmdStack[ci].outputHelp(g.Stdout)
+					g.errorf("%v\n", beforeErr)
+					g.helpOf(g.Stdout, cmdStack[ci])
 				}
 				return nil, nil, beforeErr
 			}
 
 			defer func(cmd *command) {
 				// After()
-				callErr, afterErr := g.call("After", cmd.selfV, cmdStack, cmd.args)
+				callErr, afterErr := g.call("After", cmd.selfV, cmdStack, cmd.args, ctx)
 				if callErr != nil && appRunErr == nil {
 					appRunErr = afterErr
 				}
@@ -600,21 +980,17 @@ func (g *App) exec(args []string, doRun bool) (tgt interface{}, tgtargs []string
 	if doRun {
 		funcName := "Run"
 
-		callErr, runErr := g.call(funcName, cmd.selfV, cmdStack, cmd.args)
+		callErr, runErr := g.call(funcName, cmd.selfV, cmdStack, cmd.args, ctx)
 
 		if callErr != nil {
-			if cmd == g.root {
-				g.Help(g.Stdout)
-			} else {
-				cmd.outputHelp(g.Stdout)
-			}
+			g.helpOf(g.Stdout, cmd)
 			//return ErrNotDefined
 			return nil, nil, nil
 		}
 
 		if runErr != nil {
 			if !g.SuppressErrorOutput {
-				fmt.Fprintf(g.Stderr, "%v\n", runErr)
+				g.errorf("%v\n", runErr)
 			}
 			return nil, nil, runErr
 		}
@@ -651,24 +1027,39 @@ func (g *App) arrangeName(name string, iscmd bool) string {
 	return string(result)
 }
 
-func (g *App) call(funcName string, cmd reflect.Value, cmdStack []*command, args []string) (callErr, userErr error) {
+func (g *App) call(funcName string, cmd reflect.Value, cmdStack []*command, args []string, hookArgs ...interface{}) (callErr, userErr error) {
+	retv, callErr := g.callValues(funcName, cmd, cmdStack, args, hookArgs...)
+	if callErr != nil {
+		return callErr, nil
+	}
+
+	return nil, returnErr(retv)
+}
+
+// callValues calls funcName of cmd and returns all of its return values.
+//
+// hookArgs are additional values that are passed to parameters of their types.
+func (g *App) callValues(funcName string, cmd reflect.Value, cmdStack []*command, args []string, hookArgs ...interface{}) (retv []reflect.Value, callErr error) {
 	methv := cmd.MethodByName(funcName)
 	if methv == (reflect.Value{}) {
-		return ErrNotRunnable, nil
+		return nil, ErrNotRunnable
 	}
 
 	var argv []reflect.Value
 	for i := 0; i < methv.Type().NumIn(); i++ {
 		in := methv.Type().In(i)
 
-		if in.Kind() == reflect.Struct {
+		if hv, ok := findHookArg(hookArgs, in); ok {
+			argv = append(argv, hv)
+
+		} else if in.Kind() == reflect.Struct {
 			st := findStructByType(cmdStack, in)
 			if st != nil {
 				argv = append(argv, reflect.ValueOf(st).Elem())
 			} else if reflect.TypeOf(g) == in {
 				argv = append(argv, reflect.ValueOf(*g))
 			} else {
-				return ErrNotRunnable, nil
+				return nil, ErrNotRunnable
 			}
 
 		} else if in.Kind() == reflect.Ptr && in.Elem().Kind() == reflect.Struct {
@@ -678,7 +1069,7 @@ func (g *App) call(funcName string, cmd reflect.Value, cmdStack []*command, args
 			} else if reflect.TypeOf(g) == in {
 				argv = append(argv, reflect.ValueOf(g))
 			} else {
-				return ErrNotRunnable, nil
+				return nil, ErrNotRunnable
 			}
 
 		} else if in.Kind() == reflect.Slice && in.Elem().Kind() == reflect.String {
@@ -686,13 +1077,25 @@ func (g *App) call(funcName string, cmd reflect.Value, cmdStack []*command, args
 			argv = append(argv, reflect.ValueOf(args))
 
 		} else {
-			panic("*struct, struct or []string are allowed")
+			panic("*struct, struct, []string or context.Context are allowed")
 		}
 	}
 
-	retv := methv.Call(argv)
+	return methv.Call(argv), nil
+}
 
-	return nil, returnErr(retv)
+// findHookArg finds a value of typ, or a value implementing typ (like context.Context).
+func findHookArg(hookArgs []interface{}, typ reflect.Type) (reflect.Value, bool) {
+	for _, a := range hookArgs {
+		if a == nil {
+			continue
+		}
+		at := reflect.TypeOf(a)
+		if at == typ || typ.Kind() == reflect.Interface && typ.NumMethod() > 0 && at.Implements(typ) {
+			return reflect.ValueOf(a), true
+		}
+	}
+	return reflect.Value{}, false
 }
 
 func returnErr(retv []reflect.Value) error {
@@ -720,7 +1123,7 @@ func findStructByType(stack []*command, typ reflect.Type) interface{} {
 	return nil
 }
 
-func setOptValue(opt reflect.Value, value string, tag reflect.StructTag, dectypeTag string, parsingDef bool, nondefFirstParsing *bool) error {
+func setOptValue(opt reflect.Value, value string, tag reflect.StructTag, vld *validation, dectypeTag string, parsingDef bool, nondefFirstParsing *bool) error {
 	if opt.Type().Kind() == reflect.Ptr {
 		var pv reflect.Value
 		if opt.IsNil() {
@@ -729,7 +1132,7 @@ func setOptValue(opt reflect.Value, value string, tag reflect.StructTag, dectype
 			pv = opt
 		}
 
-		err := setOptValue(pv.Elem(), value, tag, dectypeTag, parsingDef, nondefFirstParsing)
+		err := setOptValue(pv.Elem(), value, tag, vld, dectypeTag, parsingDef, nondefFirstParsing)
 		if err != nil {
 			return err
 		}
@@ -738,6 +1141,15 @@ func setOptValue(opt reflect.Value, value string, tag reflect.StructTag, dectype
 		return nil
 	}
 
+	err := decodeOptValue(opt, value, tag, dectypeTag, parsingDef, nondefFirstParsing)
+	if err != nil {
+		return err
+	}
+
+	return validateOptValue(opt, vld)
+}
+
+func decodeOptValue(opt reflect.Value, value string, tag reflect.StructTag, dectypeTag string, parsingDef bool, nondefFirstParsing *bool) error {
 	ndfp := *nondefFirstParsing
 	if !parsingDef && *nondefFirstParsing {
 		*nondefFirstParsing = false
@@ -812,7 +1224,7 @@ func errorIfEmptyRequired(cmdStack []*command) error {
 			}
 
 			if !o.assigned {
-				return errors.New("option " + o.longestName() + " is required")
+				return newParseError(KindMissingRequired, o.longestName(), c, -1, errors.New("option "+o.longestName()+" is required"))
 			}
 		}
 	}
@@ -820,13 +1232,258 @@ func errorIfEmptyRequired(cmdStack []*command) error {
 	return nil
 }
 
+// errorIfExclusive reports two options given together in a xor group of the same command.
+// Only values given in the command line count (not default, config nor env).
+func errorIfExclusive(cmdStack []*command) error {
+	for i := len(cmdStack) - 1; i >= 0; i-- {
+		c := cmdStack[i]
+		given := make(map[string]*option)
+		for _, o := range c.options {
+			if !o.cliGiven {
+				continue
+			}
+
+			for _, x := range o.xor {
+				if prev, found := given[x]; found {
+					return newParseError(KindConflict, o.longestName(), c, -1,
+						fmt.Errorf("option %s and option %s are mutually exclusive", prev.longestName(), o.longestName()))
+				}
+				given[x] = o
+			}
+		}
+	}
+
+	return nil
+}
+
+// ArgsRuleName stands for positional arguments in the conflicts tag.
+//
+//	All bool `conflicts:"@args"`
+const ArgsRuleName = "@args"
+
+// errorIfRuleBroken checks requires, requiresany and conflicts tags.
+// Names in the tags are looked up across cmdStack, from the innermost command.
+//
+// An option given in the command line, config or env triggers requires and requiresany,
+// and values of default tags satisfy them.
+// Only options given in the command line conflict.
+func (g App) errorIfRuleBroken(cmdStack []*command) error {
+	lookup := func(owner *option, tagname, name string) (*option, error) {
+		o := cmdStack[len(cmdStack)-1].findRuleOption(name)
+		if o == nil {
+			return nil, ruleNameError(owner, tagname, name)
+		}
+		return o, nil
+	}
+
+	anyAssigned := func(owner *option, names []string) (bool, error) {
+		for _, n := range names {
+			o, err := lookup(owner, g.RequiresAnyTag, n)
+			if err != nil {
+				return false, err
+			}
+			if o.assigned {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	nargs := 0
+	for _, c := range cmdStack {
+		nargs += len(c.args)
+	}
+
+	for i := len(cmdStack) - 1; i >= 0; i-- {
+		c := cmdStack[i]
+
+		if len(c.requiresAny) > 0 {
+			ok, err := anyAssigned(nil, c.requiresAny)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return newParseError(KindMissingRequired, "", c, -1,
+					fmt.Errorf("one of options %s is required", strings.Join(c.requiresAny, ", ")))
+			}
+		}
+
+		for _, o := range c.options {
+			if !o.given() {
+				continue
+			}
+
+			for _, n := range o.requires {
+				r, err := lookup(o, g.RequiresTag, n)
+				if err != nil {
+					return err
+				}
+				if !r.assigned {
+					return newParseError(KindMissingRequired, r.longestName(), c, -1,
+						fmt.Errorf("option %s requires option %s", o.longestName(), r.longestName()))
+				}
+			}
+
+			if len(o.requiresAny) > 0 {
+				ok, err := anyAssigned(o, o.requiresAny)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return newParseError(KindMissingRequired, o.longestName(), c, -1,
+						fmt.Errorf("option %s requires one of options %s", o.longestName(), strings.Join(o.requiresAny, ", ")))
+				}
+			}
+
+			if !o.cliGiven {
+				continue
+			}
+			for _, n := range o.conflicts {
+				if n == ArgsRuleName {
+					if nargs > 0 {
+						return newParseError(KindConflict, o.longestName(), c, -1,
+							fmt.Errorf("option %s conflicts with arguments", o.longestName()))
+					}
+					continue
+				}
+
+				r, err := lookup(o, g.ConflictsTag, n)
+				if err != nil {
+					return err
+				}
+				if r.cliGiven {
+					return newParseError(KindConflict, o.longestName(), c, -1,
+						fmt.Errorf("option %s conflicts with option %s", o.longestName(), r.longestName()))
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkRuleNames reports names in requires, requiresany and conflicts tags
+// not defined in cmd nor its ancestors, for cmd and its descendants.
+func (g App) checkRuleNames(cmd *command) error {
+	check := func(owner *option, tagname string, names []string) error {
+		for _, n := range names {
+			if n == ArgsRuleName && tagname == g.ConflictsTag {
+				continue
+			}
+			if cmd.findRuleOption(n) == nil {
+				return ruleNameError(owner, tagname, n)
+			}
+		}
+		return nil
+	}
+
+	if err := check(nil, g.RequiresAnyTag, cmd.requiresAny); err != nil {
+		return err
+	}
+	for _, o := range cmd.options {
+		if err := check(o, g.RequiresTag, o.requires); err != nil {
+			return err
+		}
+		if err := check(o, g.RequiresAnyTag, o.requiresAny); err != nil {
+			return err
+		}
+		if err := check(o, g.ConflictsTag, o.conflicts); err != nil {
+			return err
+		}
+	}
+
+	for _, s := range cmd.subs {
+		if err := g.checkRuleNames(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findRuleOption finds an option named in requires, requiresany or conflicts tags,
+// from c to the root.
+func (c *command) findRuleOption(name string) *option {
+	name = strings.TrimLeft(name, "-")
+	for cc := c; cc != nil; cc = cc.parent {
+		if o := cc.findOptionExact(name); o != nil {
+			return o
+		}
+	}
+	return nil
+}
+
+func ruleNameError(owner *option, tagname, name string) error {
+	name = strings.TrimLeft(name, "-")
+	if owner == nil {
+		return errors.Wrap(ErrNotDefined, fmt.Sprintf("option %s in %s tag", name, tagname))
+	}
+	return errors.Wrap(ErrNotDefined, fmt.Sprintf("option %s in %s tag of option %s", name, tagname, owner.longestName()))
+}
+
+// splitTagList splits a comma separated tag value, trimming spaces.
+func splitTagList(tv string) []string {
+	var list []string
+	for _, v := range strings.Split(tv, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// unknownCommand outputs and returns an error for a command name not defined in cmd.
+func (g App) unknownCommand(name string, cmd *command, argIdx int) error {
+	candidates := g.suggestCommands(name, cmd)
+	if !g.SuppressErrorOutput {
+		g.helpOf(g.Stdout, cmd)
+	}
+
+	perr := newParseError(KindUnknownCommand, name, cmd, argIdx, errors.Wrap(ErrNotDefined, "command "+name))
+	perr.Suggestions = candidates
+	return perr
+}
+
+// suggestCommands writes that name is not a command, with suggestions, to Stderr.
+// It returns the suggestions.
+func (g App) suggestCommands(name string, cmd *command) []string {
+	candidates := g.commandSuggestions(name, cmd)
+
+	if !g.SuppressErrorOutput {
+		g.errorf("command %q %v\n\n", name, ErrNotDefined)
+		if len(candidates) > 0 {
+			fmt.Fprintf(g.Stderr, "    maybe %v ?\n\n", candidates)
+		}
+	}
+
+	return candidates
+}
+
+// warnDeprecated writes a warning to Stderr, once for each what (like "option --old").
+func (g App) warnDeprecated(warned map[string]bool, what, deprecation string) {
+	if g.SuppressErrorOutput || warned[what] {
+		return
+	}
+	warned[what] = true
+
+	msg := what + " is deprecated"
+	if deprecation != "" {
+		msg += ": " + deprecation
+	}
+	fmt.Fprintln(g.Stderr, paint(g.themeFor(g.Stderr).Warning, msg))
+}
+
 // Help displays help messages.
 func (g App) Help(w io.Writer) {
 	if g.root == nil {
 		panic("need Bind or use NewWith")
 	}
 
-	appinfo := g.Name
+	g.writeHelp(w, g.root, false)
+}
+
+// rootHelp writes help of the app (the root command) by the built-in layout.
+func (g App) rootHelp(w io.Writer, f helpFormat) {
+	appinfo := paint(f.theme.Command, g.Name)
 	if g.Desc != "" {
 		appinfo += " - " + g.Desc
 	}
@@ -838,19 +1495,32 @@ func (g App) Help(w io.Writer) {
 	}
 
 	g.root.usage = g.Usage
+	if g.root.usage == "" && len(g.root.posArgs) > 0 {
+		g.root.usage = g.Name + " [options] " + g.root.argsUsage()
+	}
 
-	g.root.outputHelp(w)
+	g.root.outputHelp(w, f)
 
-	fmt.Fprintln(w, `
-Help sub commands:
-  help     `+g.Name+` help subcommnad subsubcommand
-  version  show version`)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, paint(f.theme.Header, "Help sub commands:"))
+	fmt.Fprintln(w, "  "+paint(f.theme.Command, "help")+"     "+g.Name+" help subcommnad subsubcommand")
+	fmt.Fprintln(w, "  "+paint(f.theme.Command, "version")+"  show version")
 
 	if g.Copyright != "" {
 		fmt.Fprintf(w, "\n%s\n", g.Copyright)
 	}
 }
 
+// fieldByName finds a field by the Go name.
+func fieldByName(fields []fieldAndPath, name string) (fieldAndPath, bool) {
+	for _, f := range fields {
+		if f.Field.Name == name {
+			return f, true
+		}
+	}
+	return fieldAndPath{}, false
+}
+
 type fieldAndPath struct {
 	Field reflect.StructField
 	Path  []int
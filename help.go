@@ -0,0 +1,345 @@
+package gli
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// HelpData is the data model of App.HelpTemplate.
+//
+// The template is executed for the root command (app help) and for sub commands (app help sub).
+//
+//	{{.Name}}{{if .Desc}} - {{.Desc}}{{end}}
+//	{{if .Subs}}
+//	COMMANDS
+//	{{range .Subs}}  {{pad (join .Names ", ") $.SubWidth}}{{.Help}}
+//	{{end}}{{end}}{{if .Options}}
+//	OPTIONS
+//	{{range .Options}}  {{pad .Label $.OptionWidth}}{{.Help}}
+//	{{end}}{{end}}{{range $g := .Inherited}}
+//	{{upper .Title}}
+//	{{range .Options}}  {{pad .Label $g.Width}}{{.Help}}
+//	{{end}}{{end}}{{if .Usage}}
+//	USAGE
+//	  {{indent 2 .Usage}}
+//	{{end}}
+//
+// Functions:
+//
+//	join    strings.Join
+//	pad     pads a string with spaces to a display width (runewidth), plus 2 spaces
+//	indent  indents second and later lines with n spaces
+//	upper   strings.ToUpper
+type HelpData struct {
+	// Name, Desc, Version and Copyright are of the App.
+	Name      string
+	Desc      string
+	Version   string
+	Copyright string
+
+	// Command is the command to be described.
+	Command HelpCommand
+	// IsRoot reports whether Command is the root command.
+	IsRoot bool
+
+	// Subs are sub commands and extra commands.
+	Subs []HelpCommand
+	// SubWidth is the max display width of joined names of Subs.
+	SubWidth int
+	// SubGroups are Subs by the category tag, in order of appearance.
+	// The first one is of uncategorized commands (Title is empty), if any.
+	SubGroups []HelpCommandGroup
+
+	// Args are positional arguments.
+	Args []HelpArg
+	// ArgWidth is the max display width of names of Args.
+	ArgWidth int
+	// Arity is the number of arguments (like "1 to 3") or empty.
+	Arity string
+
+	// Options are options of Command.
+	Options []HelpOption
+	// OptionWidth is the max display width of labels of Options.
+	OptionWidth int
+	// OptionGroups are Options by the group tag, in order of appearance.
+	// The first one is of ungrouped options (Title is empty), if any.
+	OptionGroups []HelpOptionGroup
+
+	// Inherited are options of ancestors, from the parent to the root.
+	Inherited []HelpOptionGroup
+
+	// Usage is the usage tag (App.Usage for the root), or synthesized if args are defined.
+	Usage string
+}
+
+// HelpCommand describes a command in HelpData.
+type HelpCommand struct {
+	// Name is the longest name.
+	Name string
+	// Names are all names, longer first.
+	Names []string
+	// Path is the command path, like ["sub", "subsub"].
+	Path []string
+	Help string
+	// Deprecated is like "deprecated: use new-name", or empty.
+	Deprecated string
+	// Hidden is true only for help --all.
+	Hidden bool
+	// Category is the category tag.
+	Category string
+}
+
+// HelpCommandGroup is sub commands of a category in HelpData.
+type HelpCommandGroup struct {
+	Title string
+	Subs  []HelpCommand
+}
+
+// HelpArg describes a positional argument in HelpData.
+type HelpArg struct {
+	// Name is like FILE, [FILE] or FILES...
+	Name     string
+	Help     string
+	Required bool
+	Rest     bool
+}
+
+// HelpOption describes an option in HelpData.
+type HelpOption struct {
+	// Label is like "-f, --file FILE".
+	Label string
+	// Names are hyphenated names, shorter first.
+	Names       []string
+	Placeholder string
+	Help        string
+	// Default is the defdesc tag or the default tag.
+	Default  string
+	Env      string
+	Required bool
+	// Negation is like --no-color, for bool options defaulting to true.
+	Negation string
+	// Xor is the names of mutually exclusive groups.
+	Xor []string
+	// Deprecated is like "deprecated: use --new-name", or empty.
+	Deprecated string
+	// Hidden is true only for help --all.
+	Hidden bool
+	// Group is the group tag.
+	Group string
+	// Count is true for counters (gli.Count), which are repeatable.
+	Count bool
+}
+
+// HelpOptionGroup is options of an ancestor command, or options of a group, in HelpData.
+type HelpOptionGroup struct {
+	// Title is like "Global Options" or "Outer sub Options", or the group tag.
+	Title string
+	// Command is the ancestor.
+	Command HelpCommand
+	Options []HelpOption
+	// Width is the max display width of labels of Options.
+	Width int
+}
+
+var helpTemplateFuncs = template.FuncMap{
+	"join": strings.Join,
+	"pad": func(s string, width int) string {
+		n := width - runewidth.StringWidth(s)
+		if n < 0 {
+			n = 0
+		}
+		return s + strings.Repeat(" ", n+2)
+	},
+	"indent": func(n int, s string) string {
+		return strings.ReplaceAll(s, "\n", "\n"+strings.Repeat(" ", n))
+	},
+	"upper": strings.ToUpper,
+}
+
+// helpOf outputs help of cmd, by HelpTemplate if set.
+func (g App) helpOf(w io.Writer, cmd *command) {
+	g.writeHelp(w, cmd, false)
+}
+
+// writeHelp is helpOf, including hidden options and commands if all (help --all).
+func (g App) writeHelp(w io.Writer, cmd *command, all bool) {
+	if g.HelpTemplate != "" && g.executeHelpTemplate(w, cmd, all) {
+		return
+	}
+
+	f := g.helpFormat(w)
+	f.all = all
+
+	if cmd == g.root {
+		g.rootHelp(w, f)
+		return
+	}
+	cmd.outputHelp(w, f)
+}
+
+// executeHelpTemplate reports false (and outputs to Stderr) on an error.
+func (g App) executeHelpTemplate(w io.Writer, cmd *command, all bool) bool {
+	var buf bytes.Buffer
+
+	tmpl, err := template.New("help").Funcs(helpTemplateFuncs).Parse(g.HelpTemplate)
+	if err == nil {
+		err = tmpl.Execute(&buf, g.helpData(cmd, all))
+	}
+	if err != nil {
+		g.errorf("HelpTemplate: %v\n", err)
+		return false
+	}
+
+	_, _ = buf.WriteTo(w)
+	return true
+}
+
+func (g App) helpData(cmd *command, all bool) *HelpData {
+	d := &HelpData{
+		Name:      g.Name,
+		Desc:      g.Desc,
+		Version:   g.Version,
+		Copyright: g.Copyright,
+		Command:   helpCommandOf(cmd),
+		IsRoot:    cmd.parent == nil,
+	}
+
+	for _, s := range cmd.visibleSubs(all) {
+		hc := helpCommandOf(s)
+		d.Subs = append(d.Subs, hc)
+		if sw := runewidth.StringWidth(strings.Join(hc.Names, ", ")); d.SubWidth < sw {
+			d.SubWidth = sw
+		}
+	}
+
+	var categories []string
+	for _, s := range d.Subs {
+		categories = append(categories, s.Category)
+	}
+	titles, members := helpSections(categories)
+	for si, title := range titles {
+		grp := HelpCommandGroup{Title: title}
+		for _, i := range members[si] {
+			grp.Subs = append(grp.Subs, d.Subs[i])
+		}
+		d.SubGroups = append(d.SubGroups, grp)
+	}
+
+	for _, a := range cmd.posArgs {
+		d.Args = append(d.Args, HelpArg{
+			Name:     a.usageName(),
+			Help:     a.help,
+			Required: a.required,
+			Rest:     a.rest,
+		})
+		if aw := runewidth.StringWidth(a.usageName()); d.ArgWidth < aw {
+			d.ArgWidth = aw
+		}
+	}
+	if cmd.arity != nil {
+		d.Arity = cmd.arity.String()
+	}
+
+	d.Options, d.OptionWidth = helpOptionsOf(cmd, cmd.visibleOptions(all))
+	var groups []string
+	for _, o := range d.Options {
+		groups = append(groups, o.Group)
+	}
+	titles, members = helpSections(groups)
+	for si, title := range titles {
+		grp := HelpOptionGroup{
+			Title:   title,
+			Command: d.Command,
+			Width:   d.OptionWidth,
+		}
+		for _, i := range members[si] {
+			grp.Options = append(grp.Options, d.Options[i])
+		}
+		d.OptionGroups = append(d.OptionGroups, grp)
+	}
+
+	for curr := cmd.parent; curr != nil; curr = curr.parent {
+		opts := curr.visibleOptions(all)
+		if len(opts) == 0 {
+			continue
+		}
+
+		title := "Global Options"
+		if curr.parent != nil {
+			title = "Outer " + curr.names[0] + " Options"
+		}
+
+		grp := HelpOptionGroup{
+			Title:   title,
+			Command: helpCommandOf(curr),
+		}
+		grp.Options, grp.Width = helpOptionsOf(curr, opts)
+		d.Inherited = append(d.Inherited, grp)
+	}
+
+	if cmd.parent == nil {
+		d.Usage = strings.TrimSpace(g.Usage)
+		if d.Usage == "" && len(cmd.posArgs) > 0 {
+			d.Usage = g.Name + " [options] " + cmd.argsUsage()
+		}
+	} else {
+		d.Usage = strings.TrimSpace(cmd.usage)
+		if d.Usage == "" && len(cmd.posArgs) > 0 {
+			d.Usage = strings.Join(append(cmd.longestNameStack(), "[options]", cmd.argsUsage()), " ")
+		}
+	}
+
+	return d
+}
+
+func helpCommandOf(c *command) HelpCommand {
+	names := append([]string{}, c.names...)
+	sort.SliceStable(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+
+	return HelpCommand{
+		Name:  c.longestName(),
+		Names: names,
+		Path:  c.longestNameStack(),
+		Help:  c.help,
+
+		Deprecated: deprecationNote(c.deprecated, c.deprecation),
+		Hidden:     c.hidden,
+		Category:   c.category,
+	}
+}
+
+func helpOptionsOf(c *command, options []*option) (opts []HelpOption, width int) {
+	for _, o := range options {
+		names := o.hyphenedNames()
+		label := strings.Join(names, ", ")
+		if o.placeholder != "" {
+			label += " " + o.placeholder
+		}
+
+		opts = append(opts, HelpOption{
+			Label:       label,
+			Names:       names,
+			Placeholder: o.placeholder,
+			Help:        o.help,
+			Default:     o.defaultDesc(),
+			Env:         o.env,
+			Required:    o.required,
+			Negation:    o.noName(c.autoNoBoolOptions),
+			Xor:         o.xor,
+			Deprecated:  deprecationNote(o.deprecated, o.deprecation),
+			Hidden:      o.hidden,
+			Group:       o.group,
+			Count:       o.counter,
+		})
+		if lw := runewidth.StringWidth(label); width < lw {
+			width = lw
+		}
+	}
+
+	return opts, width
+}
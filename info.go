@@ -0,0 +1,240 @@
+package gli
+
+import (
+	"reflect"
+)
+
+// CommandInfo is a read-only view of a command discovered by Bind.
+//
+//	app := gli.NewWith(&globalCmd{})
+//	for _, sub := range app.Root().Commands() {
+//	    fmt.Println(sub.Name(), sub.Help())
+//	}
+type CommandInfo struct {
+	cmd *command
+}
+
+// OptionInfo is a read-only view of an option discovered by Bind.
+type OptionInfo struct {
+	opt *option
+	cmd *command
+}
+
+// Root returns the root command.
+func (g App) Root() *CommandInfo {
+	if g.root == nil {
+		panic("need Bind or use NewWith")
+	}
+
+	return &CommandInfo{cmd: g.root}
+}
+
+// Name returns the longest name. The root command has no name.
+func (c CommandInfo) Name() string {
+	return c.cmd.longestName()
+}
+
+// Names returns all names including aliases.
+func (c CommandInfo) Names() []string {
+	return append([]string(nil), c.cmd.names...)
+}
+
+// Path returns longest names from the root. The root command has an empty path.
+func (c CommandInfo) Path() []string {
+	return c.cmd.longestNameStack()
+}
+
+// Help returns the help tag.
+func (c CommandInfo) Help() string {
+	return c.cmd.help
+}
+
+// Usage returns the usage tag.
+func (c CommandInfo) Usage() string {
+	return c.cmd.usage
+}
+
+// Arity returns the number of arguments allowed by the args tag.
+// max < 0 means unlimited. ok is false if not constrained.
+func (c CommandInfo) Arity() (min, max int, ok bool) {
+	if c.cmd.arity == nil {
+		return 0, -1, false
+	}
+	return c.cmd.arity.min, c.cmd.arity.max, true
+}
+
+// Deprecated returns the deprecated tag (or Deprecated of AddExtraCommand).
+// ok is false if not deprecated.
+func (c CommandInfo) Deprecated() (message string, ok bool) {
+	return c.cmd.deprecation, c.cmd.deprecated
+}
+
+// Hidden reports whether the command is hidden by the hidden tag (or Hidden of AddExtraCommand).
+func (c CommandInfo) Hidden() bool {
+	return c.cmd.hidden
+}
+
+// Category returns the category tag (or Category of AddExtraCommand).
+func (c CommandInfo) Category() string {
+	return c.cmd.category
+}
+
+// Tag returns the struct tag of the command field.
+// The root command and extra commands have no tag.
+func (c CommandInfo) Tag() reflect.StructTag {
+	return c.cmd.tag
+}
+
+// FieldPath returns the index sequence of the command field in its parent struct (for reflect.Value.FieldByIndex).
+// The root command and extra commands return nil.
+func (c CommandInfo) FieldPath() []int {
+	return append([]int(nil), c.cmd.fieldPath...)
+}
+
+// Type returns the struct type of the command.
+func (c CommandInfo) Type() reflect.Type {
+	return c.cmd.selfT
+}
+
+// IsRoot reports whether the command is the root command.
+func (c CommandInfo) IsRoot() bool {
+	return c.cmd.parent == nil
+}
+
+// IsExtra reports whether the command is added by AddExtraCommand.
+func (c CommandInfo) IsExtra() bool {
+	if c.cmd.parent == nil {
+		return false
+	}
+	for _, e := range c.cmd.parent.extras {
+		if e == c.cmd {
+			return true
+		}
+	}
+	return false
+}
+
+// Parent returns the parent command, or nil for the root command.
+func (c CommandInfo) Parent() *CommandInfo {
+	if c.cmd.parent == nil {
+		return nil
+	}
+	return &CommandInfo{cmd: c.cmd.parent}
+}
+
+// Commands returns sub commands, followed by extra commands.
+func (c CommandInfo) Commands() []*CommandInfo {
+	var result []*CommandInfo
+	for _, s := range c.cmd.subs {
+		result = append(result, &CommandInfo{cmd: s})
+	}
+	for _, s := range c.cmd.extras {
+		result = append(result, &CommandInfo{cmd: s})
+	}
+	return result
+}
+
+// Options returns options of the command (not including ones of ancestors).
+func (c CommandInfo) Options() []*OptionInfo {
+	var result []*OptionInfo
+	for _, o := range c.cmd.options {
+		result = append(result, &OptionInfo{opt: o, cmd: c.cmd})
+	}
+	return result
+}
+
+// Name returns the longest name.
+func (o OptionInfo) Name() string {
+	return o.opt.longestName()
+}
+
+// Names returns all names including aliases.
+func (o OptionInfo) Names() []string {
+	return append([]string(nil), o.opt.names...)
+}
+
+// Help returns the help tag.
+func (o OptionInfo) Help() string {
+	return o.opt.help
+}
+
+// Placeholder returns the placeholder in the cli tag (`cli:"name=PLACEHOLDER"`).
+func (o OptionInfo) Placeholder() string {
+	return o.opt.placeholder
+}
+
+// Default returns the default tag.
+func (o OptionInfo) Default() string {
+	return o.opt.defValue
+}
+
+// DefDesc returns the defdesc tag.
+func (o OptionInfo) DefDesc() string {
+	return o.opt.defDesc
+}
+
+// Env returns the env tag.
+func (o OptionInfo) Env() string {
+	return o.opt.env
+}
+
+// Required returns the required tag.
+func (o OptionInfo) Required() bool {
+	return o.opt.required
+}
+
+// DecType returns the type tag, which is used to lookup a TypeDecoder.
+func (o OptionInfo) DecType() string {
+	return o.opt.dectype
+}
+
+// ConfigKey returns the key in config files. Empty if excluded by `config:"-"`.
+func (o OptionInfo) ConfigKey() string {
+	return o.opt.configKey
+}
+
+// IsCount reports whether the option is a counter (gli.Count or type:"Count").
+func (o OptionInfo) IsCount() bool {
+	return o.opt.counter
+}
+
+// TakesArg reports whether the option requires an argument (not a bool option nor a counter).
+func (o OptionInfo) TakesArg() bool {
+	return o.opt.takesArg()
+}
+
+// Deprecated returns the deprecated tag. ok is false if not deprecated.
+func (o OptionInfo) Deprecated() (message string, ok bool) {
+	return o.opt.deprecation, o.opt.deprecated
+}
+
+// Hidden reports whether the option is hidden by the hidden tag.
+func (o OptionInfo) Hidden() bool {
+	return o.opt.hidden
+}
+
+// Group returns the group tag.
+func (o OptionInfo) Group() string {
+	return o.opt.group
+}
+
+// Tag returns the struct tag of the option field.
+func (o OptionInfo) Tag() reflect.StructTag {
+	return o.opt.tag
+}
+
+// FieldPath returns the index sequence of the option field in the command struct (for reflect.Value.FieldByIndex).
+// For the mapto tag, it is of the mapped field.
+func (o OptionInfo) FieldPath() []int {
+	return append([]int(nil), o.opt.fieldIdx...)
+}
+
+// Type returns the Go type of the option field (the mapped field for the mapto tag).
+func (o OptionInfo) Type() reflect.Type {
+	return o.opt.typ
+}
+
+// Command returns the command having the option.
+func (o OptionInfo) Command() *CommandInfo {
+	return &CommandInfo{cmd: o.cmd}
+}
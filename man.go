@@ -0,0 +1,326 @@
+package gli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ManSection is the section number of man pages.
+const ManSection = "1"
+
+// ManPage writes a man page (roff) of the whole app to w.
+// Sub commands are described in the COMMANDS section.
+func (g App) ManPage(w io.Writer) error {
+	if g.root == nil {
+		panic("need Bind or use NewWith")
+	}
+
+	m := manWriter{w: w, app: &g}
+	m.header(g.root)
+	m.synopsis(g.root)
+	m.options(g.root, "OPTIONS")
+
+	if len(g.root.visibleSubs(false)) > 0 {
+		m.section("COMMANDS")
+		g.root.walkVisible(func(c *command) {
+			if c == g.root {
+				return
+			}
+			m.commandDetail(c)
+		})
+	}
+
+	m.usage(g.root)
+	m.environment(g.root, true)
+	m.footer()
+
+	return nil
+}
+
+// ManPages writes man pages into dir, one for each command.
+// File names are like app.1, app-sub.1 and app-sub-subsub.1.
+// It returns paths of written files.
+func (g App) ManPages(dir string) ([]string, error) {
+	if g.root == nil {
+		panic("need Bind or use NewWith")
+	}
+
+	var paths []string
+	var err error
+
+	g.root.walkVisible(func(c *command) {
+		if err != nil {
+			return
+		}
+
+		path := filepath.Join(dir, g.manPageName(c)+"."+ManSection)
+
+		var f *os.File
+		f, err = os.Create(path)
+		if err != nil {
+			return
+		}
+		defer f.Close()
+
+		m := manWriter{w: f, app: &g}
+		m.header(c)
+		m.synopsis(c)
+		m.options(c, "OPTIONS")
+		for curr := c.parent; curr != nil; curr = curr.parent {
+			if curr.parent == nil {
+				m.options(curr, "GLOBAL OPTIONS")
+			} else {
+				m.options(curr, "OPTIONS OF "+strings.ToUpper(strings.Join(curr.longestNameStack(), " ")))
+			}
+		}
+		m.commands(c)
+		m.usage(c)
+		m.environment(c, false)
+		m.seeAlso(c)
+		m.footer()
+
+		paths = append(paths, path)
+	})
+
+	return paths, err
+}
+
+// manPageName is app, app-sub, app-sub-subsub, ...
+func (g App) manPageName(c *command) string {
+	return strings.Join(append([]string{g.Name}, c.longestNameStack()...), "-")
+}
+
+type manWriter struct {
+	w   io.Writer
+	app *App
+}
+
+// roffEscape escapes backslashes, hyphens and leading control characters.
+func roffEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	s = strings.ReplaceAll(s, "-", `\-`)
+
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if strings.HasPrefix(l, ".") || strings.HasPrefix(l, "'") {
+			lines[i] = `\&` + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// roffQuote makes s a quoted macro argument.
+func roffQuote(s string) string {
+	return `"` + strings.ReplaceAll(roffEscape(s), `"`, `\(dq`) + `"`
+}
+
+func (m manWriter) section(title string) {
+	fmt.Fprintf(m.w, ".SH %s\n", title)
+}
+
+func (m manWriter) header(c *command) {
+	title := strings.ToUpper(m.app.manPageName(c))
+	source := m.app.Name
+	if m.app.Version != "" {
+		source += " " + m.app.Version
+	}
+	fmt.Fprintf(m.w, ".TH %s %s \"\" %s \"User Commands\"\n", roffQuote(title), roffQuote(ManSection), roffQuote(source))
+
+	m.section("NAME")
+	desc := c.help
+	if c.parent == nil {
+		desc = m.app.Desc
+	}
+	if desc != "" {
+		fmt.Fprintf(m.w, "%s \\- %s\n", roffEscape(m.app.manPageName(c)), roffEscape(desc))
+	} else {
+		fmt.Fprintf(m.w, "%s\n", roffEscape(m.app.manPageName(c)))
+	}
+}
+
+func (m manWriter) synopsis(c *command) {
+	m.section("SYNOPSIS")
+	fmt.Fprintf(m.w, ".B %s\n", roffEscape(strings.Join(append([]string{m.app.Name}, c.longestNameStack()...), " ")))
+	if len(c.visibleOptions(false)) > 0 {
+		fmt.Fprintln(m.w, `[\fIOPTIONS\fR]`)
+	}
+	if len(c.visibleSubs(false)) > 0 {
+		fmt.Fprintln(m.w, `[\fICOMMAND\fR]`)
+	}
+	if len(c.posArgs) > 0 {
+		fmt.Fprintln(m.w, roffEscape(c.argsUsage()))
+	} else {
+		fmt.Fprintln(m.w, `[\fIARGS\fR...]`)
+	}
+}
+
+func (m manWriter) options(c *command, title string) {
+	if len(c.visibleOptions(false)) == 0 {
+		return
+	}
+
+	m.section(title)
+	m.optionList(c)
+}
+
+func (m manWriter) optionList(c *command) {
+	for _, o := range c.visibleOptions(false) {
+		var names []string
+		for _, n := range o.hyphenedNames() {
+			names = append(names, `\fB`+roffEscape(n)+`\fR`)
+		}
+		line := strings.Join(names, ", ")
+		if o.placeholder != "" {
+			line += ` \fI` + roffEscape(o.placeholder) + `\fR`
+		}
+
+		fmt.Fprintln(m.w, ".TP")
+		fmt.Fprintln(m.w, line)
+		if o.help != "" {
+			fmt.Fprintln(m.w, roffEscape(o.help))
+		}
+
+		var notes []string
+		if d := o.defaultDesc(); d != "" {
+			notes = append(notes, "Default: "+d+".")
+		}
+		if o.env != "" {
+			notes = append(notes, "Environment: "+o.env+".")
+		}
+		if o.required {
+			notes = append(notes, "Required.")
+		}
+		if len(notes) > 0 {
+			if o.help != "" {
+				fmt.Fprintln(m.w, ".br")
+			}
+			fmt.Fprintln(m.w, roffEscape(strings.Join(notes, " ")))
+		}
+		if n := o.noName(c.autoNoBoolOptions); n != "" {
+			fmt.Fprintln(m.w, ".br")
+			fmt.Fprintf(m.w, "\\fB%s\\fR to disable.\n", roffEscape(n))
+		}
+	}
+}
+
+func (m manWriter) commands(c *command) {
+	subs := c.visibleSubs(false)
+	if len(subs) == 0 {
+		return
+	}
+
+	m.section("COMMANDS")
+	for _, s := range subs {
+		var names []string
+		for _, n := range s.names {
+			names = append(names, `\fB`+roffEscape(n)+`\fR`)
+		}
+		fmt.Fprintln(m.w, ".TP")
+		fmt.Fprintln(m.w, strings.Join(names, ", "))
+		if s.help != "" {
+			fmt.Fprintln(m.w, roffEscape(s.help))
+			fmt.Fprintln(m.w, ".br")
+		}
+		fmt.Fprintf(m.w, "See \\fB%s\\fR(%s).\n", roffEscape(m.app.manPageName(s)), ManSection)
+	}
+}
+
+// commandDetail describes a sub command in the combined page.
+func (m manWriter) commandDetail(c *command) {
+	fmt.Fprintf(m.w, ".SS %s\n", roffQuote(strings.Join(append([]string{m.app.Name}, c.longestNameStack()...), " ")))
+	if len(c.names) > 1 {
+		fmt.Fprintf(m.w, "Aliases: %s\n", roffEscape(strings.Join(c.names, ", ")))
+		fmt.Fprintln(m.w, ".br")
+	}
+	if c.help != "" {
+		fmt.Fprintln(m.w, roffEscape(c.help))
+	}
+	if len(c.visibleOptions(false)) > 0 {
+		fmt.Fprintln(m.w, ".PP")
+		fmt.Fprintln(m.w, "Options:")
+		fmt.Fprintln(m.w, ".RS")
+		m.optionList(c)
+		fmt.Fprintln(m.w, ".RE")
+	}
+	if c.usage != "" {
+		fmt.Fprintln(m.w, ".PP")
+		fmt.Fprintln(m.w, "Usage:")
+		fmt.Fprintln(m.w, ".RS")
+		fmt.Fprintln(m.w, ".nf")
+		fmt.Fprintln(m.w, roffEscape(strings.TrimSpace(c.usage)))
+		fmt.Fprintln(m.w, ".fi")
+		fmt.Fprintln(m.w, ".RE")
+	}
+}
+
+func (m manWriter) usage(c *command) {
+	usage := c.usage
+	if c.parent == nil {
+		usage = m.app.Usage
+	}
+	if usage == "" {
+		return
+	}
+
+	m.section("USAGE")
+	fmt.Fprintln(m.w, ".nf")
+	fmt.Fprintln(m.w, roffEscape(strings.TrimSpace(usage)))
+	fmt.Fprintln(m.w, ".fi")
+}
+
+// environment lists env tags of c and its ancestors (and descendants if deep).
+func (m manWriter) environment(c *command, deep bool) {
+	var opts []*option
+	collect := func(cmd *command) {
+		for _, o := range cmd.visibleOptions(false) {
+			if o.env != "" {
+				opts = append(opts, o)
+			}
+		}
+	}
+	if deep {
+		c.walkVisible(collect)
+	} else {
+		for curr := c; curr != nil; curr = curr.parent {
+			collect(curr)
+		}
+	}
+	if len(opts) == 0 {
+		return
+	}
+
+	m.section("ENVIRONMENT")
+	for _, o := range opts {
+		fmt.Fprintln(m.w, ".TP")
+		fmt.Fprintf(m.w, "\\fB%s\\fR\n", roffEscape(o.env))
+		fmt.Fprintf(m.w, "Sets \\fB%s\\fR.\n", roffEscape(hyphenate(o.longestName())))
+	}
+}
+
+func (m manWriter) seeAlso(c *command) {
+	var refs []string
+	if c.parent != nil {
+		refs = append(refs, `\fB`+roffEscape(m.app.manPageName(c.parent))+`\fR(`+ManSection+`)`)
+	}
+	for _, s := range c.visibleSubs(false) {
+		refs = append(refs, `\fB`+roffEscape(m.app.manPageName(s))+`\fR(`+ManSection+`)`)
+	}
+	if len(refs) == 0 {
+		return
+	}
+
+	m.section("SEE ALSO")
+	fmt.Fprintln(m.w, strings.Join(refs, ", "))
+}
+
+func (m manWriter) footer() {
+	if m.app.Copyright == "" {
+		return
+	}
+
+	m.section("COPYRIGHT")
+	fmt.Fprintln(m.w, roffEscape(m.app.Copyright))
+}
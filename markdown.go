@@ -0,0 +1,195 @@
+package gli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WriteMarkdown writes Markdown documents into dir, one for each command.
+// File names are like app.md, app-sub.md and app-sub-subsub.md,
+// and pages are linked to each other.
+//
+// It is handy to be called by a program for go generate.
+func (g App) WriteMarkdown(dir string) error {
+	if g.root == nil {
+		panic("need Bind or use NewWith")
+	}
+
+	var err error
+
+	g.root.walkVisible(func(c *command) {
+		if err != nil {
+			return
+		}
+
+		var f *os.File
+		f, err = os.Create(filepath.Join(dir, g.markdownFileName(c)))
+		if err != nil {
+			return
+		}
+		defer f.Close()
+
+		g.outputMarkdown(f, c)
+	})
+
+	return err
+}
+
+func (g App) markdownFileName(c *command) string {
+	return g.manPageName(c) + ".md"
+}
+
+func (g App) outputMarkdown(w io.Writer, c *command) {
+	fmt.Fprintf(w, "# %s\n", strings.Join(append([]string{g.Name}, c.longestNameStack()...), " "))
+
+	desc := c.help
+	if c.parent == nil {
+		desc = g.Desc
+		if g.Version != "" {
+			desc = strings.TrimSpace(desc + " (" + g.Version + ")")
+		}
+	}
+	if desc != "" {
+		fmt.Fprintf(w, "\n%s\n", desc)
+	}
+
+	if len(c.names) > 1 {
+		var names []string
+		for _, n := range c.names {
+			names = append(names, "`"+n+"`")
+		}
+		fmt.Fprintf(w, "\nAliases: %s\n", strings.Join(names, ", "))
+	}
+
+	if c.parent != nil {
+		fmt.Fprintf(w, "\nParent: [%s](%s)\n", strings.Join(append([]string{g.Name}, c.parent.longestNameStack()...), " "), g.markdownFileName(c.parent))
+	}
+
+	usage := c.usage
+	if c.parent == nil {
+		usage = g.Usage
+	}
+	if strings.TrimSpace(usage) == "" {
+		usage = g.markdownUsage(c)
+	}
+	fmt.Fprintf(w, "\n## Usage\n\n```\n%s\n```\n", strings.TrimSpace(usage))
+
+	if len(c.posArgs) > 0 || c.arity != nil {
+		fmt.Fprintln(w, "\n## Arguments")
+		if c.arity != nil {
+			fmt.Fprintf(w, "\nNumber of arguments: %s.\n", c.arity)
+		}
+	}
+	if len(c.posArgs) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "| Argument | Required | Description |")
+		fmt.Fprintln(w, "|----------|----------|-------------|")
+		for _, a := range c.posArgs {
+			name := a.name
+			if a.rest {
+				name += "..."
+			}
+			var required string
+			if a.required {
+				required = "yes"
+			}
+			fmt.Fprintf(w, "| `%s` | %s | %s |\n", name, required, markdownCell(a.help))
+		}
+	}
+
+	if subs := c.visibleSubs(false); len(subs) > 0 {
+		fmt.Fprintln(w, "\n## Commands")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "| Command | Aliases | Description |")
+		fmt.Fprintln(w, "|---------|---------|-------------|")
+		for _, s := range subs {
+			lname := s.longestName()
+			var aliases []string
+			for _, n := range s.names {
+				if n != lname {
+					aliases = append(aliases, "`"+n+"`")
+				}
+			}
+			fmt.Fprintf(w, "| [%s](%s) | %s | %s |\n", lname, g.markdownFileName(s), strings.Join(aliases, ", "), markdownCell(s.help))
+		}
+	}
+
+	writeMarkdownOptions(w, c, "Options")
+	for curr := c.parent; curr != nil; curr = curr.parent {
+		if curr.parent == nil {
+			writeMarkdownOptions(w, curr, "Global Options")
+		} else {
+			writeMarkdownOptions(w, curr, "Options of "+strings.Join(curr.longestNameStack(), " "))
+		}
+	}
+
+	if c.parent == nil && g.Copyright != "" {
+		fmt.Fprintf(w, "\n---\n\n%s\n", g.Copyright)
+	}
+}
+
+// markdownUsage is a usage line like the SYNOPSIS of man pages.
+func (g App) markdownUsage(c *command) string {
+	words := append([]string{g.Name}, c.longestNameStack()...)
+	if len(c.visibleOptions(false)) > 0 {
+		words = append(words, "[OPTIONS]")
+	}
+	if len(c.visibleSubs(false)) > 0 {
+		words = append(words, "[COMMAND]")
+	}
+	if len(c.posArgs) > 0 {
+		words = append(words, c.argsUsage())
+	} else {
+		words = append(words, "[ARGS...]")
+	}
+	return strings.Join(words, " ")
+}
+
+func writeMarkdownOptions(w io.Writer, c *command, title string) {
+	opts := c.visibleOptions(false)
+	if len(opts) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "\n## %s\n\n", title)
+	fmt.Fprintln(w, "| Option | Placeholder | Default | Env | Required | Description |")
+	fmt.Fprintln(w, "|--------|-------------|---------|-----|----------|-------------|")
+	for _, o := range opts {
+		var names []string
+		for _, n := range o.hyphenedNames() {
+			names = append(names, "`"+n+"`")
+		}
+		if n := o.noName(c.autoNoBoolOptions); n != "" {
+			names = append(names, "`"+n+"`")
+		}
+
+		var def, env, required string
+		if d := o.defaultDesc(); d != "" {
+			def = "`" + d + "`"
+		}
+		if o.env != "" {
+			env = "`" + o.env + "`"
+		}
+		if o.required {
+			required = "yes"
+		}
+
+		fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %s |\n",
+			strings.Join(names, ", "),
+			markdownCell(o.placeholder),
+			markdownCell(def),
+			env,
+			required,
+			markdownCell(o.help))
+	}
+}
+
+// markdownCell escapes s to be put in a table cell.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	s = strings.ReplaceAll(strings.TrimSpace(s), "\n", "<br>")
+	return s
+}
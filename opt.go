@@ -2,17 +2,27 @@ package gli
 
 import (
 	"reflect"
+	"sort"
+	"strconv"
 )
 
 type option struct {
 	names []string
 
-	env      string
-	defValue string
-	defDesc  string
+	env       string
+	configKey string
+	defValue  string
+	defDesc   string
 
-	required bool
-	assigned bool
+	required  bool
+	assigned  bool
+	defaulted bool // assigned only by the default tag
+	cliGiven  bool // given in the command line
+
+	xor         []string
+	requires    []string
+	requiresAny []string
+	conflicts   []string
 
 	dectype string
 
@@ -21,10 +31,20 @@ type option struct {
 
 	placeholder string
 
+	deprecated  bool
+	deprecation string // a message like "use --new-name"
+	hidden      bool
+	group       string // a section in help
+	counter     bool   // gli.Count or type:"Count"
+
+	validation *validation // nil if no validation tags
+
 	ownerV   reflect.Value
 	fieldIdx []int
+	typ      reflect.Type
 
-	nondefFirstParsing bool
+	// shared by options writing the same field (mapto)
+	nondefFirstParsing *bool
 }
 
 func (o option) longestName() string {
@@ -40,3 +60,42 @@ func (o option) longestName() string {
 
 	return maxname
 }
+
+// given reports whether the option is assigned by other than the default tag.
+func (o option) given() bool {
+	return o.assigned && !o.defaulted
+}
+
+// takesArg reports whether the option consumes an argument (--opt value).
+func (o option) takesArg() bool {
+	return o.typ.Kind() != reflect.Bool && !o.counter
+}
+
+// hyphenedNames returns names like [-n, --name], shorter first.
+func (o option) hyphenedNames() []string {
+	var names []string
+	for _, n := range o.names {
+		names = append(names, hyphenate(n))
+	}
+	sort.SliceStable(names, func(i, j int) bool { return len(names[i]) < len(names[j]) })
+	return names
+}
+
+// defaultDesc returns the defdesc tag or the default tag.
+func (o option) defaultDesc() string {
+	if o.defDesc != "" {
+		return o.defDesc
+	}
+	return o.defValue
+}
+
+// noName returns --no-name if the option is a bool option defaulting to true.
+func (o option) noName(autoNoBoolOptions bool) string {
+	if !autoNoBoolOptions || o.takesArg() || o.counter {
+		return ""
+	}
+	if b, err := strconv.ParseBool(o.defValue); err != nil || !b {
+		return ""
+	}
+	return "--no-" + o.longestName()
+}
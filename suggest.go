@@ -0,0 +1,128 @@
+package gli
+
+import (
+	"sort"
+	"strings"
+)
+
+// suggest returns names similar to input, from the most similar.
+// Each group (names of an option or a command) contributes its most similar name.
+//
+// A name is similar if input is a prefix of it,
+// or the Damerau-Levenshtein distance is within threshold (and less than the length of the name).
+func suggest(input string, groups [][]string, threshold int) []string {
+	if threshold <= 0 || input == "" {
+		return nil
+	}
+
+	type candidate struct {
+		name string
+		dist int
+	}
+	var candidates []candidate
+
+	for _, names := range groups {
+		best := candidate{dist: -1}
+		for _, n := range names {
+			d := damerauLevenshtein(input, n)
+			if strings.HasPrefix(n, input) {
+				d = 0
+			} else if d > threshold || d >= len([]rune(n)) {
+				continue
+			}
+			if best.dist < 0 || d < best.dist || d == best.dist && len(n) > len(best.name) {
+				best = candidate{name: n, dist: d}
+			}
+		}
+		if best.dist >= 0 {
+			candidates = append(candidates, best)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].dist != candidates[j].dist {
+			return candidates[i].dist < candidates[j].dist
+		}
+		return candidates[i].name < candidates[j].name
+	})
+
+	var result []string
+	for _, c := range candidates {
+		dup := false
+		for _, r := range result {
+			dup = dup || r == c.name
+		}
+		if !dup {
+			result = append(result, c.name)
+		}
+	}
+	return result
+}
+
+// damerauLevenshtein is the edit distance (optimal string alignment) between a and b,
+// counting insertions, deletions, substitutions and transpositions of adjacent runes.
+func damerauLevenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := 0; j <= len(rb); j++ {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			d[i][j] = minInt(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[len(ra)][len(rb)]
+}
+
+func minInt(v int, vs ...int) int {
+	for _, w := range vs {
+		if w < v {
+			v = w
+		}
+	}
+	return v
+}
+
+// optionSuggestions returns option names similar to name, from cmd and its ancestors.
+func (g App) optionSuggestions(name string, cmd *command) []string {
+	var groups [][]string
+	for curr := cmd; curr != nil; curr = curr.parent {
+		for _, o := range curr.visibleOptions(false) {
+			names := append([]string{}, o.names...)
+			if g.AutoNoBoolOptions && !o.takesArg() {
+				for _, n := range o.names {
+					names = append(names, "no-"+n)
+				}
+			}
+			groups = append(groups, names)
+		}
+	}
+
+	return suggest(name, groups, g.SuggestDistance)
+}
+
+// commandSuggestions returns sub command names of cmd similar to name.
+func (g App) commandSuggestions(name string, cmd *command) []string {
+	var groups [][]string
+	for _, s := range cmd.visibleSubs(false) {
+		groups = append(groups, s.names)
+	}
+
+	return suggest(name, groups, g.SuggestDistance)
+}
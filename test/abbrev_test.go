@@ -0,0 +1,94 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shu-go/gli/v2"
+	"github.com/shu-go/gotwant"
+)
+
+type abbrevGlobal struct {
+	Verbose bool `cli:"v,verbose"`
+	Version bool
+	Color   bool `default:"true"`
+
+	List   abbrevList `cli:"ls,list"`
+	Lock   struct{}
+	Remove struct{} `cli:"rm,remove"`
+}
+
+type abbrevList struct {
+	Format string `cli:"f,format"`
+	Limit  int
+}
+
+func newAbbrevApp(g *abbrevGlobal) gli.App {
+	app := newApp(g)
+	app.AllowAbbrev = true
+	return app
+}
+
+func TestAbbrev(t *testing.T) {
+	t.Run("Expand", func(t *testing.T) {
+		g := abbrevGlobal{}
+		app := newAbbrevApp(&g)
+		tgt, args, err := app.Parse([]string{"--verb", "--no-col", "li", "--form", "json", "--lim=3", "arg"})
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, g.Verbose, true)
+		gotwant.Test(t, g.Color, false)
+		_, ok := tgt.(*abbrevList)
+		gotwant.Test(t, ok, true)
+		gotwant.Test(t, g.List.Format, "json")
+		gotwant.Test(t, g.List.Limit, 3)
+		gotwant.Test(t, args, []string{"arg"})
+
+		g = abbrevGlobal{}
+		app = newAbbrevApp(&g)
+		_, _, err = app.Parse([]string{"rem"})
+		gotwant.TestError(t, err, nil)
+
+		// exact names win
+		g = abbrevGlobal{}
+		app = newAbbrevApp(&g)
+		_, _, err = app.Parse([]string{"ls", "-f", "li"})
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, g.List.Format, "li")
+	})
+
+	t.Run("AfterArgs", func(t *testing.T) {
+		g := abbrevGlobal{}
+		app := newAbbrevApp(&g)
+		_, args, err := app.Parse([]string{"list", "x", "--lim", "--form"})
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, args, []string{"x", "--lim", "--form"})
+		gotwant.Test(t, g.List.Limit, 0)
+
+		g = abbrevGlobal{}
+		app = newAbbrevApp(&g)
+		_, args, err = app.Parse([]string{"x", "--verb", "li"})
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, args, []string{"x", "--verb", "li"})
+		gotwant.Test(t, g.Verbose, false)
+	})
+
+	t.Run("Ambiguous", func(t *testing.T) {
+		app := newAbbrevApp(&abbrevGlobal{})
+		_, _, err := app.Parse([]string{"--ver"})
+		gotwant.TestError(t, err, "option ver is ambiguous: verbose, version")
+		var perr *gli.ParseError
+		gotwant.Test(t, errors.As(err, &perr), true)
+		gotwant.Test(t, perr.Kind, gli.KindAmbiguous)
+		gotwant.Test(t, perr.Suggestions, []string{"verbose", "version"})
+
+		app = newAbbrevApp(&abbrevGlobal{})
+		_, _, err = app.Parse([]string{"l"})
+		gotwant.TestError(t, err, "command l is ambiguous: list, lock")
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		app := newApp(&abbrevGlobal{})
+		_, _, err := app.Parse([]string{"--verb"})
+		gotwant.TestError(t, err, gli.ErrNotDefined)
+	})
+}
@@ -0,0 +1,194 @@
+package test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shu-go/gli/v2"
+	"github.com/shu-go/gotwant"
+)
+
+type argGlobal struct {
+	Copy argCopy `cli:"cp,copy"`
+	Pick argPick
+}
+
+type argCopy struct {
+	Src  string     `arg:"0" required:"true"`
+	Due  *time.Time `arg:"1=DATE"`
+	Nums []int      `args:"rest=NUMS"`
+
+	Force bool
+}
+
+type argPick struct {
+	First  int    `arg:"0"`
+	Second string `arg:"1" type:"Choice" choices:"a,b"`
+}
+
+func TestArgs(t *testing.T) {
+	t.Run("All", func(t *testing.T) {
+		g := argGlobal{}
+		app := newApp(&g)
+		_, args, err := app.Parse([]string{"cp", "--force", "src.txt", "2020-01-02", "1", "2", "3"})
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, g.Copy.Src, "src.txt")
+		gotwant.Test(t, *g.Copy.Due, time.Date(2020, 1, 2, 0, 0, 0, 0, time.Local))
+		gotwant.Test(t, g.Copy.Nums, []int{1, 2, 3})
+		gotwant.Test(t, g.Copy.Force, true)
+		gotwant.Test(t, args, []string{"src.txt", "2020-01-02", "1", "2", "3"})
+	})
+
+	t.Run("Optional", func(t *testing.T) {
+		g := argGlobal{}
+		app := newApp(&g)
+		_, _, err := app.Parse([]string{"cp", "src.txt"})
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, g.Copy.Src, "src.txt")
+		gotwant.TestExpr(t, g.Copy.Due, g.Copy.Due == nil)
+		gotwant.TestExpr(t, g.Copy.Nums, g.Copy.Nums == nil)
+	})
+
+	t.Run("Required", func(t *testing.T) {
+		app := newApp(&argGlobal{})
+		_, _, err := app.Parse([]string{"cp"})
+		gotwant.TestExpr(t, err, err != nil && err.Error() == "argument SRC is required")
+	})
+
+	t.Run("TooMany", func(t *testing.T) {
+		g := argGlobal{}
+		app := newApp(&g)
+		_, _, err := app.Parse([]string{"pick", "1", "a"})
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, g.Pick.First, 1)
+		gotwant.Test(t, g.Pick.Second, "a")
+
+		app = newApp(&g)
+		_, _, err = app.Parse([]string{"pick", "1", "a", "extra"})
+		gotwant.TestError(t, err, "wrong number of arguments: 3 given, at most 2 expected")
+	})
+
+	t.Run("Decode", func(t *testing.T) {
+		app := newApp(&argGlobal{})
+		_, _, err := app.Parse([]string{"pick", "x"})
+		gotwant.TestExpr(t, err, err != nil && strings.HasPrefix(err.Error(), "argument FIRST"))
+
+		app = newApp(&argGlobal{})
+		_, _, err = app.Parse([]string{"pick", "1", "c"})
+		gotwant.TestExpr(t, err, err != nil && strings.HasPrefix(err.Error(), "argument SECOND"))
+
+		app = newApp(&argGlobal{})
+		_, _, err = app.Parse([]string{"cp", "s", "2020-01-02", "1", "x"})
+		gotwant.TestExpr(t, err, err != nil && strings.HasPrefix(err.Error(), "argument NUMS"))
+	})
+
+	t.Run("Duplicate", func(t *testing.T) {
+		app := gli.New()
+		err := app.Bind(&struct {
+			Src string `arg:"0"`
+			Dst string `arg:"0"`
+		}{})
+		gotwant.TestError(t, err, "arg Dst: position 0 is already bound to SRC")
+
+		app = gli.New()
+		err = app.Bind(&struct {
+			Files []string `args:"rest"`
+			More  []string `args:"rest"`
+		}{})
+		gotwant.TestError(t, err, "args More: the rest is already bound to FILES")
+	})
+
+	t.Run("NotSlice", func(t *testing.T) {
+		app := gli.New()
+		err := app.Bind(&struct {
+			Rest string `args:"rest"`
+		}{})
+		gotwant.TestExpr(t, err, err != nil)
+	})
+}
+
+type arityGlobal struct {
+	_ struct{} `args:"..1"`
+
+	Open  arityOpen `args:"1"`
+	Tag   arityTag
+	Touch arityTouch `args:"2.."`
+}
+
+type arityOpen struct{}
+
+type arityTag struct {
+	_ struct{} `help:"tag IDs" args:"1..3"`
+}
+
+type arityTouch struct{}
+
+func TestArity(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		for _, args := range [][]string{
+			{},
+			{"a"},
+			{"open", "a"},
+			{"tag", "a"},
+			{"tag", "a", "b", "c"},
+			{"touch", "a", "b", "c", "d"},
+		} {
+			app := newApp(&arityGlobal{})
+			_, _, err := app.Parse(args)
+			gotwant.TestError(t, err, nil, gotwant.Desc(strings.Join(args, " ")))
+		}
+	})
+
+	t.Run("NG", func(t *testing.T) {
+		app := newApp(&arityGlobal{})
+		_, _, err := app.Parse([]string{"a", "b"})
+		gotwant.TestExpr(t, err, err != nil && err.Error() == "wrong number of arguments: 2 given, at most 1 expected")
+
+		app = newApp(&arityGlobal{})
+		_, _, err = app.Parse([]string{"open"})
+		gotwant.TestExpr(t, err, err != nil && err.Error() == "wrong number of arguments: 0 given, exactly 1 expected")
+
+		app = newApp(&arityGlobal{})
+		_, _, err = app.Parse([]string{"tag", "a", "b", "c", "d"})
+		gotwant.TestExpr(t, err, err != nil && err.Error() == "wrong number of arguments: 4 given, 1 to 3 expected")
+
+		app = newApp(&arityGlobal{})
+		_, _, err = app.Parse([]string{"touch", "a"})
+		gotwant.TestExpr(t, err, err != nil && err.Error() == "wrong number of arguments: 1 given, at least 2 expected")
+	})
+
+	t.Run("Extra", func(t *testing.T) {
+		app := newApp(&arityGlobal{})
+		app.AddExtraCommand(&arityOpen{}, "view", "", gli.Args("1"))
+		_, _, err := app.Parse([]string{"view", "a"})
+		gotwant.TestError(t, err, nil)
+
+		app = newApp(&arityGlobal{})
+		app.AddExtraCommand(&arityOpen{}, "view", "", gli.Args("1"))
+		_, _, err = app.Parse([]string{"view"})
+		gotwant.TestExpr(t, err, err != nil)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		type invalid struct {
+			Sub arityOpen `args:"3..1"`
+		}
+		app := gli.New()
+		err := app.Bind(&invalid{})
+		gotwant.TestExpr(t, err, err != nil)
+
+		// neither rest nor a range
+		app = gli.New()
+		err = app.Bind(&struct {
+			Files []string `args:"rset"`
+		}{})
+		gotwant.TestError(t, err, `field Files: args: invalid range "rset"`)
+	})
+
+	t.Run("Help", func(t *testing.T) {
+		app := newApp(&arityGlobal{})
+		out, _ := runWithStdout(t, &app, "help", "tag")
+		gotwant.TestExpr(t, out, strings.Contains(out, "Arguments (1 to 3):"))
+	})
+}
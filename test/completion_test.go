@@ -0,0 +1,149 @@
+package test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/shu-go/gli/v2"
+	"github.com/shu-go/gotwant"
+)
+
+type complGlobal struct {
+	List complList `cli:"ls,list" help:"list items"`
+
+	File    string `cli:"f,file=FILE" help:"file name"`
+	Verbose bool   `cli:"v,verbose"`
+}
+
+type complList struct {
+	Format string `cli:"format" type:"Choice" choices:"json,text"`
+	Color  bool   `default:"true"`
+}
+
+func TestCompletion(t *testing.T) {
+	t.Run("Bash", func(t *testing.T) {
+		app := newApp(&complGlobal{})
+		app.Name = "my-app"
+
+		buf := &bytes.Buffer{}
+		gotwant.TestError(t, app.Completion(buf, "bash"), nil)
+		s := buf.String()
+		gotwant.TestExpr(t, s, strings.Contains(s, "complete -o default -F _my_app my-app"))
+		gotwant.TestExpr(t, s, strings.Contains(s, `":ls"|":list") cmdpath="list" ;;`))
+		gotwant.TestExpr(t, s, strings.Contains(s, `cmds='ls list help version'`))
+		gotwant.TestExpr(t, s, strings.Contains(s, `opts='-f --file -v --verbose'`))
+		gotwant.TestExpr(t, s, strings.Contains(s, `opts='--format --color --no-color'`))
+		gotwant.TestExpr(t, s, strings.Contains(s, `compgen -W 'json text'`))
+	})
+
+	t.Run("Zsh", func(t *testing.T) {
+		app := newApp(&complGlobal{})
+		app.Name = "app"
+
+		buf := &bytes.Buffer{}
+		gotwant.TestError(t, app.Completion(buf, "zsh"), nil)
+		s := buf.String()
+		gotwant.TestExpr(t, s, strings.HasPrefix(s, "#compdef app"))
+		gotwant.TestExpr(t, s, strings.Contains(s, `'list:list items'`))
+		gotwant.TestExpr(t, s, strings.Contains(s, `compadd -- 'json' 'text'`))
+	})
+
+	t.Run("Fish", func(t *testing.T) {
+		app := newApp(&complGlobal{})
+		app.Name = "app"
+
+		buf := &bytes.Buffer{}
+		gotwant.TestError(t, app.Completion(buf, "fish"), nil)
+		s := buf.String()
+		gotwant.TestExpr(t, s, strings.Contains(s, `complete -c app -f -n '__app_cmdpath_is ""' -a 'list' -d 'list items'`))
+		gotwant.TestExpr(t, s, strings.Contains(s, `complete -c app -n '__app_cmdpath_is "list"' -l format -x -a 'json text' -d ''`))
+	})
+
+	t.Run("UnknownShell", func(t *testing.T) {
+		app := newApp(&complGlobal{})
+		err := app.Completion(&bytes.Buffer{}, "cmd.exe")
+		gotwant.TestError(t, err, gli.ErrNotDefined)
+	})
+
+	t.Run("SubCommand", func(t *testing.T) {
+		g := struct {
+			Sub struct{}
+		}{}
+		app := newApp(&g)
+		gotwant.TestError(t, app.Run([]string{"completion", "fish"}), nil)
+		gotwant.TestError(t, app.Run([]string{"completion", "tcsh"}), gli.ErrNotDefined)
+	})
+}
+
+type dynComplList struct {
+	Format string `cli:"format" type:"Choice" choices:"json,text"`
+	Owner  string `cli:"owner"`
+}
+
+func (l dynComplList) Complete(g *dynComplGlobal, args []string, comp gli.Completing) []string {
+	switch comp.Option {
+	case "owner":
+		return []string{"alice", "bob"}
+	case "":
+		return []string{"item" + g.Prefix + "1", "item" + g.Prefix + "2", "other"}
+	}
+	return nil
+}
+
+type dynComplGlobal struct {
+	List dynComplList `cli:"ls,list"`
+
+	Prefix string `cli:"prefix"`
+}
+
+// runWithStdout runs app and returns its output to Stdout.
+func runWithStdout(t *testing.T, app *gli.App, args ...string) (string, error) {
+	t.Helper()
+
+	f, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	app.Stdout = f
+	runErr := app.Run(args)
+
+	content, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content), runErr
+}
+
+func runCompletion(t *testing.T, args ...string) []string {
+	t.Helper()
+
+	app := newApp(&dynComplGlobal{})
+	out, err := runWithStdout(t, &app, append([]string{"__complete"}, args...)...)
+	gotwant.TestError(t, err, nil)
+	return strings.Fields(out)
+}
+
+func TestDynamicCompletion(t *testing.T) {
+	t.Run("Commands", func(t *testing.T) {
+		gotwant.Test(t, runCompletion(t, ""), []string{"ls", "list", "help", "version"})
+		gotwant.Test(t, runCompletion(t, "li"), []string{"list"})
+		gotwant.Test(t, runCompletion(t, "he"), []string{"help"})
+		gotwant.Test(t, runCompletion(t, "list", ""), []string{"item1", "item2", "other"})
+	})
+	t.Run("Options", func(t *testing.T) {
+		gotwant.Test(t, runCompletion(t, "list", "--"), []string{"--format", "--owner"})
+	})
+	t.Run("Args", func(t *testing.T) {
+		gotwant.Test(t, runCompletion(t, "--prefix", "X", "list", "a", "it"), []string{"itemX1", "itemX2"})
+	})
+	t.Run("OptionValues", func(t *testing.T) {
+		gotwant.Test(t, runCompletion(t, "list", "--format", ""), []string{"json", "text"})
+		gotwant.Test(t, runCompletion(t, "list", "--format=j"), []string{"--format=json"})
+		gotwant.Test(t, runCompletion(t, "list", "--format", "=", "t"), []string{"text"})
+		gotwant.Test(t, runCompletion(t, "list", "--owner", "b"), []string{"bob"})
+	})
+}
@@ -0,0 +1,222 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/shu-go/gli/v2"
+	"github.com/shu-go/gotwant"
+)
+
+type configGlobal struct {
+	File  string   `default:"./default.json"`
+	Names []string `cli:"names" env:"TEST_CONFIG_NAMES"`
+	Port  int      `config:"server.port" env:"TEST_CONFIG_PORT"`
+	Skip  string   `config:"-"`
+
+	Sub configSub `cli:"s,sub"`
+}
+
+type configSub struct {
+	Done bool
+	Mode string `type:"Choice" choices:"a,b"`
+}
+
+func TestConfig(t *testing.T) {
+	t.Run("JSON", func(t *testing.T) {
+		g := configGlobal{}
+		app := newApp(&g)
+		err := app.LoadConfig(strings.NewReader(`{
+			"file": "./config.json",
+			"names": ["a", "b"],
+			"server": {"port": 8080},
+			"skip": "skipped",
+			"sub": {"done": true}
+		}`), "json")
+		gotwant.TestError(t, err, nil)
+
+		err = app.Run([]string{"sub"})
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, g.File, "./config.json")
+		gotwant.Test(t, g.Names, []string{"a", "b"})
+		gotwant.Test(t, g.Port, 8080)
+		gotwant.Test(t, g.Skip, "")
+		gotwant.Test(t, g.Sub.Done, true)
+	})
+
+	t.Run("INI", func(t *testing.T) {
+		g := configGlobal{}
+		app := newApp(&g)
+		err := app.LoadConfig(strings.NewReader(`
+# comment
+file = "./config.ini" # comment
+names = ['x', "y"]
+
+[server]
+port = 9090
+
+[sub]
+done = true
+`), "ini")
+		gotwant.TestError(t, err, nil)
+
+		err = app.Run([]string{"s"})
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, g.File, "./config.ini")
+		gotwant.Test(t, g.Names, []string{"x", "y"})
+		gotwant.Test(t, g.Port, 9090)
+		gotwant.Test(t, g.Sub.Done, true)
+	})
+
+	t.Run("Precedence", func(t *testing.T) {
+		g := configGlobal{}
+		app := newApp(&g)
+		err := app.LoadConfig(strings.NewReader("file = ./config\nserver.port = 1\nnames = a"), "toml")
+		gotwant.TestError(t, err, nil)
+
+		os.Setenv("TEST_CONFIG_PORT", "2")
+		defer os.Setenv("TEST_CONFIG_PORT", "")
+
+		err = app.Run([]string{"--names", "z"})
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, g.File, "./config")
+		gotwant.Test(t, g.Port, 2)
+		gotwant.Test(t, g.Names, []string{"z"})
+	})
+
+	t.Run("JSONMapAndComma", func(t *testing.T) {
+		type global struct {
+			Labels map[string]string
+			Names  []string
+		}
+		g := global{}
+		app := newApp(&g)
+		err := app.LoadConfig(strings.NewReader(`{
+			"labels": {"a": "1", "B": "x,y"},
+			"names": ["x,y", "z"]
+		}`), "json")
+		gotwant.TestError(t, err, nil)
+
+		err = app.Run([]string{})
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, g.Labels, map[string]string{"a": "1", "B": "x,y"})
+		gotwant.Test(t, g.Names, []string{"x,y", "z"})
+	})
+
+	t.Run("EnvReplacesConfig", func(t *testing.T) {
+		g := configGlobal{}
+		app := newApp(&g)
+		err := app.LoadConfig(strings.NewReader(`{"names": ["c1", "c2"]}`), "json")
+		gotwant.TestError(t, err, nil)
+
+		os.Setenv("TEST_CONFIG_NAMES", "e1")
+		defer os.Setenv("TEST_CONFIG_NAMES", "")
+
+		err = app.Run([]string{})
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, g.Names, []string{"e1"})
+	})
+
+	t.Run("ConfigFile", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.json")
+		os.WriteFile(path, []byte(`{"sub": {"mode": "b"}}`), 0644)
+
+		g := configGlobal{}
+		app := newApp(&g)
+		app.ConfigFile = path
+		err := app.Run([]string{"sub"})
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, g.Sub.Mode, "b")
+
+		os.WriteFile(path, []byte(`{"sub": {"mode": "c"}}`), 0644)
+		g = configGlobal{}
+		app = newApp(&g)
+		app.ConfigFile = path
+		err = app.Run([]string{"sub"})
+		gotwant.TestExpr(t, err, err != nil && strings.Contains(err.Error(), "config sub.mode"))
+
+		g = configGlobal{}
+		app = newApp(&g)
+		app.ConfigFile = filepath.Join(t.TempDir(), "notexist.json")
+		err = app.Run([]string{"sub"})
+		gotwant.TestError(t, err, nil)
+	})
+
+	t.Run("Format", func(t *testing.T) {
+		app := newApp(&configGlobal{})
+		gotwant.TestError(t, app.LoadConfig(strings.NewReader(""), "yaml"), gli.ErrNotDefined)
+		err := app.LoadConfig(strings.NewReader("novalue"), "ini")
+		gotwant.TestExpr(t, err, err != nil)
+	})
+}
+
+type discoveryGlobal struct {
+	Name  string
+	Port  int
+	Debug bool
+
+	Sub struct {
+		Mode string
+		Deep string
+	}
+}
+
+func (g discoveryGlobal) Run(app *gli.App) {
+	discoveredFiles = app.LoadedConfigFiles()
+}
+
+var discoveredFiles []string
+
+func TestConfigDiscovery(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		// os.UserConfigDir does not follow XDG_CONFIG_HOME
+		t.Skip("XDG_CONFIG_HOME is not used on " + runtime.GOOS)
+	}
+
+	tmp := t.TempDir()
+	xdg := filepath.Join(tmp, "xdg")
+	proj := filepath.Join(tmp, "proj")
+	work := filepath.Join(proj, "work")
+	os.MkdirAll(filepath.Join(xdg, "myapp"), 0755)
+	os.MkdirAll(work, 0755)
+
+	userConf := filepath.Join(xdg, "myapp", "config.json")
+	projRC := filepath.Join(proj, ".myapprc")
+	workRC := filepath.Join(work, ".myapprc")
+	os.WriteFile(userConf, []byte(`{"name": "user", "port": 1, "sub": {"mode": "user", "deep": "user"}}`), 0644)
+	os.WriteFile(projRC, []byte("port = 2\n[sub]\nmode = proj\n"), 0644)
+	os.WriteFile(workRC, []byte(`{"debug": true}`), 0644)
+
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	wd, _ := os.Getwd()
+	os.Chdir(work)
+	defer os.Chdir(wd)
+
+	g := discoveryGlobal{}
+	app := newApp(&g)
+	app.Name = "myapp.exe"
+	app.ConfigDiscovery = true
+
+	paths := app.ConfigSearchPaths()
+	gotwant.Test(t, paths[len(paths)-1], workRC)
+	gotwant.Test(t, paths[len(paths)-2], projRC)
+
+	err := app.Run([]string{})
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, g.Name, "user")
+	gotwant.Test(t, g.Port, 2)
+	gotwant.Test(t, g.Debug, true)
+	gotwant.Test(t, discoveredFiles, []string{userConf, projRC, workRC})
+
+	g = discoveryGlobal{}
+	app = newApp(&g)
+	app.Name = "myapp"
+	app.ConfigDiscovery = true
+	err = app.Run([]string{"sub"})
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, g.Sub.Mode, "proj")
+	gotwant.Test(t, g.Sub.Deep, "user")
+}
@@ -0,0 +1,89 @@
+package test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/shu-go/gotwant"
+)
+
+type ctxKey struct{}
+
+type ctxGlobal struct {
+	result string
+	Sub    ctxSub
+}
+
+func (g *ctxGlobal) Before(ctx context.Context) {
+	g.result += ":before=" + ctxValue(ctx)
+}
+
+func (g *ctxGlobal) After(ctx context.Context) {
+	g.result += ":after"
+	if ctx.Err() != nil {
+		g.result += "(canceled)"
+	}
+}
+
+type ctxSub struct{}
+
+func (s ctxSub) Run(ctx context.Context, g *ctxGlobal, args []string) error {
+	g.result += ":run=" + ctxValue(ctx)
+
+	if len(args) > 0 && args[0] == "wait" {
+		p, err := os.FindProcess(os.Getpid())
+		if err == nil {
+			err = p.Signal(os.Interrupt)
+		}
+		if err != nil {
+			g.result += ":nosignal"
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			g.result += ":done"
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+			g.result += ":timeout"
+		}
+	}
+	return nil
+}
+
+func ctxValue(ctx context.Context) string {
+	v, _ := ctx.Value(ctxKey{}).(string)
+	return v
+}
+
+func TestContext(t *testing.T) {
+	t.Run("Run", func(t *testing.T) {
+		g := ctxGlobal{}
+		app := newApp(&g)
+		err := app.Run([]string{"sub"})
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, g.result, ":before=:run=:after")
+	})
+
+	t.Run("RunContext", func(t *testing.T) {
+		g := ctxGlobal{}
+		app := newApp(&g)
+		ctx := context.WithValue(context.Background(), ctxKey{}, "v")
+		err := app.RunContext(ctx, []string{"sub"})
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, g.result, ":before=v:run=v:after")
+	})
+
+	t.Run("Signal", func(t *testing.T) {
+		g := ctxGlobal{}
+		app := newApp(&g)
+		err := app.RunContext(context.Background(), []string{"sub", "wait"})
+		if g.result == ":before=:run=:nosignal:after" {
+			t.Skip("signals are not supported")
+		}
+		gotwant.TestError(t, err, context.Canceled)
+		gotwant.Test(t, g.result, ":before=:run=:done:after(canceled)")
+	})
+}
@@ -0,0 +1,90 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shu-go/gli/v2"
+	"github.com/shu-go/gotwant"
+)
+
+type countGlobal struct {
+	Verbose gli.Count `cli:"v,verbose" help:"verbosity"`
+	Debug   int       `cli:"d,debug" type:"Count" default:"1"`
+	Quiet   bool      `cli:"q"`
+	File    string    `cli:"f"`
+
+	Sub countSub
+}
+
+type countSub struct {
+	Level gli.Count `cli:"l,level"`
+}
+
+func TestCount(t *testing.T) {
+	for _, tc := range []struct {
+		args    []string
+		verbose gli.Count
+		debug   int
+		file    string
+		sub     gli.Count
+	}{
+		{args: nil, verbose: 0, debug: 1},
+		{args: []string{"-v"}, verbose: 1, debug: 1},
+		{args: []string{"-vvv"}, verbose: 3, debug: 1},
+		{args: []string{"-v", "--verbose", "-v"}, verbose: 3, debug: 1},
+		{args: []string{"--verbose=5"}, verbose: 5, debug: 1},
+		{args: []string{"-v=2", "-v"}, verbose: 3, debug: 1},
+		{args: []string{"-vvv", "--verbose=2"}, verbose: 2, debug: 1},
+		{args: []string{"-vv", "--no-verbose"}, verbose: 0, debug: 1},
+		{args: []string{"-d"}, verbose: 0, debug: 1},
+		{args: []string{"-dd", "-d"}, verbose: 0, debug: 3},
+		{args: []string{"-vqdvf", "x"}, verbose: 2, debug: 1, file: "x"},
+		{args: []string{"sub", "-lll"}, sub: 3, debug: 1},
+		{args: []string{"-v", "sub", "--level=2"}, verbose: 1, debug: 1, sub: 2},
+	} {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			g := countGlobal{}
+			app := newApp(&g)
+			err := app.Run(tc.args)
+			gotwant.TestError(t, err, nil)
+			gotwant.Test(t, g.Verbose, tc.verbose)
+			gotwant.Test(t, g.Debug, tc.debug)
+			gotwant.Test(t, g.File, tc.file)
+			gotwant.Test(t, g.Sub.Level, tc.sub)
+		})
+	}
+
+	t.Run("AfterArgs", func(t *testing.T) {
+		g := countGlobal{}
+		app := newApp(&g)
+		_, args, err := app.Parse([]string{"x", "--verbose=3", "-v=2"})
+		gotwant.TestError(t, err, nil)
+		// the parser splits args at = (x a=b -> [x a = b]), but values are not lost
+		gotwant.Test(t, args, []string{"x", "--verbose", "=", "3", "-v", "=", "2"})
+		gotwant.Test(t, g.Verbose, gli.Count(0))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		g := countGlobal{}
+		app := newApp(&g)
+		err := app.Run([]string{"--verbose=x"})
+		gotwant.TestError(t, err, "verbose")
+
+		app = gli.New()
+		err = app.Bind(&struct {
+			Verbose string `type:"Count"`
+		}{})
+		gotwant.TestError(t, err, "integer")
+	})
+
+	t.Run("Help", func(t *testing.T) {
+		app := newApp(&countGlobal{})
+		out, err := runWithStdout(t, &app, "help")
+		gotwant.TestError(t, err, nil)
+		gotwant.TestExpr(t, out, strings.Contains(out, "  -v, --verbose  verbosity (repeatable)\n"), gotwant.Desc(out))
+		gotwant.TestExpr(t, out, strings.Contains(out, "  -d, --debug     (repeatable default: 1)\n"), gotwant.Desc(out))
+		gotwant.Test(t, app.Root().Options()[0].IsCount(), true)
+		gotwant.Test(t, app.Root().Options()[0].TakesArg(), false)
+	})
+}
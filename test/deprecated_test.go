@@ -0,0 +1,126 @@
+package test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/shu-go/gli/v2"
+	"github.com/shu-go/gotwant"
+)
+
+type deprGlobal struct {
+	Tags    []string `cli:"tag"`
+	OldTags []string `cli:"old-tag" help:"tags" deprecated:"use --tag" mapto:"Tags"`
+	Legacy  bool     `help:"legacy mode" deprecated:""`
+
+	Sub    deprSub `help:"a sub command"`
+	OldSub deprSub `cli:"oldsub" deprecated:"use sub"`
+}
+
+type deprSub struct {
+	Name string
+}
+
+func runWithStderr(t *testing.T, app *gli.App, args ...string) (string, error) {
+	t.Helper()
+
+	f, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	app.SuppressErrorOutput = false
+	app.Stderr = f
+	_, runErr := runWithStdout(t, app, args...)
+
+	content, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content), runErr
+}
+
+func TestDeprecated(t *testing.T) {
+	t.Run("Option", func(t *testing.T) {
+		g := deprGlobal{}
+		app := newApp(&g)
+
+		errout, err := runWithStderr(t, &app, "--old-tag", "a", "--old-tag", "b", "--legacy")
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, g.Tags, []string{"a", "b"})
+		gotwant.Test(t, g.OldTags, []string(nil))
+		gotwant.Test(t, g.Legacy, true)
+		gotwant.Test(t, errout, "option --old-tag is deprecated: use --tag\noption --legacy is deprecated\n")
+	})
+
+	t.Run("MixedNames", func(t *testing.T) {
+		g := deprGlobal{}
+		app := newApp(&g)
+
+		_, err := runWithStderr(t, &app, "--tag", "a", "--old-tag", "b", "--tag", "c")
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, g.Tags, []string{"a", "b", "c"})
+	})
+
+	t.Run("Command", func(t *testing.T) {
+		g := deprGlobal{}
+		app := newApp(&g)
+
+		errout, err := runWithStderr(t, &app, "oldsub", "--name", "x")
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, g.OldSub.Name, "x")
+		gotwant.Test(t, errout, "command oldsub is deprecated: use sub\n")
+	})
+
+	t.Run("Extra", func(t *testing.T) {
+		app := newApp(&deprGlobal{})
+		app.AddExtraCommand(&deprSub{}, "extra", "an extra command", gli.Deprecated("use sub"))
+
+		errout, err := runWithStderr(t, &app, "extra")
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, errout, "command extra is deprecated: use sub\n")
+	})
+
+	t.Run("Suppressed", func(t *testing.T) {
+		g := deprGlobal{}
+		app := newApp(&g)
+
+		_, err := runWithStdout(t, &app, "--old-tag", "a")
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, g.Tags, []string{"a"})
+	})
+
+	t.Run("Help", func(t *testing.T) {
+		app := newApp(&deprGlobal{})
+
+		out, err := runWithStdout(t, &app, "help")
+		gotwant.TestError(t, err, nil)
+		gotwant.TestExpr(t, out, strings.Contains(out, "  --old-tag  tags (deprecated: use --tag)\n"), gotwant.Desc(out))
+		gotwant.TestExpr(t, out, strings.Contains(out, "  --legacy   legacy mode (deprecated)\n"), gotwant.Desc(out))
+		gotwant.TestExpr(t, out, strings.Contains(out, "  oldsub  (deprecated: use sub)\n"), gotwant.Desc(out))
+	})
+
+	t.Run("Info", func(t *testing.T) {
+		app := newApp(&deprGlobal{})
+
+		msg, ok := app.Root().Options()[1].Deprecated()
+		gotwant.Test(t, msg, "use --tag")
+		gotwant.Test(t, ok, true)
+		_, ok = app.Root().Options()[0].Deprecated()
+		gotwant.Test(t, ok, false)
+
+		msg, ok = app.Root().Commands()[1].Deprecated()
+		gotwant.Test(t, msg, "use sub")
+		gotwant.Test(t, ok, true)
+	})
+
+	t.Run("MapToNotDefined", func(t *testing.T) {
+		app := gli.New()
+		err := app.Bind(&struct {
+			Old string `mapto:"New"`
+		}{})
+		gotwant.TestError(t, err, gli.ErrNotDefined)
+	})
+}
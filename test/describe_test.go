@@ -0,0 +1,118 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/shu-go/gli/v2"
+	"github.com/shu-go/gotwant"
+)
+
+type descGlobal struct {
+	List descList `cli:"ls,list" help:"list items" usage:"app list"`
+
+	File    string `cli:"f,file=FILE" help:"file name" default:"./todo.json" defdesc:"./todo.json in cwd" env:"APP_FILE"`
+	Verbose bool   `cli:"v,verbose"`
+}
+
+type descList struct {
+	Format string `type:"Choice" choices:"json, text" required:"true"`
+}
+
+type descExtra struct{}
+
+func TestDescribeJSON(t *testing.T) {
+	t.Run("WriteJSON", func(t *testing.T) {
+		app := newApp(&descGlobal{})
+		app.Name = "app"
+		app.Desc = "a todo app"
+		app.AddExtraCommand(&descExtra{}, "extra, ex", "an extra command")
+
+		buf := &bytes.Buffer{}
+		gotwant.TestError(t, app.WriteJSON(buf), nil)
+
+		var got map[string]interface{}
+		gotwant.TestError(t, json.Unmarshal(buf.Bytes(), &got), nil)
+
+		want := map[string]interface{}{
+			"name": "app",
+			"desc": "a todo app",
+			"command": map[string]interface{}{
+				"name": "",
+				"path": []interface{}{},
+				"options": []interface{}{
+					map[string]interface{}{
+						"name":        "file",
+						"aliases":     []interface{}{"f"},
+						"help":        "file name",
+						"placeholder": "FILE",
+						"default":     "./todo.json",
+						"defdesc":     "./todo.json in cwd",
+						"env":         "APP_FILE",
+						"required":    false,
+						"goType":      "string",
+						"takesArg":    true,
+					},
+					map[string]interface{}{
+						"name":     "verbose",
+						"aliases":  []interface{}{"v"},
+						"required": false,
+						"goType":   "bool",
+						"takesArg": false,
+						"negation": "no-verbose",
+					},
+				},
+				"commands": []interface{}{
+					map[string]interface{}{
+						"name":    "list",
+						"aliases": []interface{}{"ls"},
+						"path":    []interface{}{"list"},
+						"help":    "list items",
+						"usage":   "app list",
+						"options": []interface{}{
+							map[string]interface{}{
+								"name":     "format",
+								"required": true,
+								"type":     "Choice",
+								"goType":   "string",
+								"choices":  []interface{}{"json", "text"},
+								"takesArg": true,
+							},
+						},
+					},
+					map[string]interface{}{
+						"name":    "extra",
+						"aliases": []interface{}{"ex"},
+						"path":    []interface{}{"extra"},
+						"help":    "an extra command",
+						"extra":   true,
+					},
+				},
+			},
+		}
+		gotwant.Test(t, got, want)
+	})
+
+	t.Run("HelpJSON", func(t *testing.T) {
+		app := newApp(&descGlobal{})
+		app.Name = "app"
+
+		out, err := runWithStdout(t, &app, "help", "--json")
+		gotwant.TestError(t, err, nil)
+		var got map[string]interface{}
+		gotwant.TestError(t, json.Unmarshal([]byte(out), &got), nil)
+		gotwant.Test(t, got["name"], "app")
+
+		out, err = runWithStdout(t, &app, "help", "ls", "--json")
+		gotwant.TestError(t, err, nil)
+		got = nil
+		gotwant.TestError(t, json.Unmarshal([]byte(out), &got), nil)
+		gotwant.Test(t, got["name"], "list")
+	})
+
+	t.Run("NotHelp", func(t *testing.T) {
+		app := newApp(&descGlobal{})
+		gotwant.TestError(t, app.Run([]string{"--json"}), gli.ErrNotDefined)
+	})
+}
@@ -0,0 +1,105 @@
+package test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/shu-go/gli/v2"
+	"github.com/shu-go/gotwant"
+)
+
+type errGlobal struct {
+	Verbose bool `cli:"v,verbose"`
+	Level   int  `max:"5"`
+
+	List errList `cli:"ls,list"`
+}
+
+type errList struct {
+	Name  string `required:"true"`
+	Limit int
+
+	Files []int `args:"rest"`
+}
+
+func TestParseError(t *testing.T) {
+	parse := func(args ...string) *gli.ParseError {
+		t.Helper()
+
+		app := newApp(&errGlobal{})
+		_, _, err := app.Parse(args)
+		var perr *gli.ParseError
+		if !errors.As(err, &perr) {
+			t.Fatalf("%v is not a ParseError", err)
+		}
+		return perr
+	}
+
+	t.Run("UnknownOption", func(t *testing.T) {
+		perr := parse("-v", "ls", "--name", "a", "--limt", "1")
+		gotwant.Test(t, perr.Kind, gli.KindUnknownOption)
+		gotwant.Test(t, perr.Name, "limt")
+		gotwant.Test(t, perr.Path, []string{"list"})
+		gotwant.Test(t, perr.Index, 4)
+		gotwant.Test(t, perr.Suggestions, []string{"limit"})
+		gotwant.Test(t, errors.Is(perr, gli.ErrNotDefined), true)
+	})
+
+	t.Run("InvalidValue", func(t *testing.T) {
+		perr := parse("--level=9")
+		gotwant.Test(t, perr.Kind, gli.KindInvalidValue)
+		gotwant.Test(t, perr.Name, "level")
+		gotwant.Test(t, perr.Index, 0)
+		var verr *gli.ValidationError
+		gotwant.Test(t, errors.As(perr, &verr), true)
+	})
+
+	t.Run("DecodeFailure", func(t *testing.T) {
+		perr := parse("ls", "--name", "a", "--limit", "abc")
+		gotwant.Test(t, perr.Kind, gli.KindDecodeFailure)
+		gotwant.Test(t, perr.Name, "limit")
+		gotwant.Test(t, perr.Index, 3)
+		gotwant.Test(t, perr.Error(), `option limit: strconv.ParseInt: parsing "abc": invalid syntax`)
+		var nerr *strconv.NumError
+		gotwant.Test(t, errors.As(perr, &nerr), true)
+
+		perr = parse("ls", "--name", "a", "1", "x")
+		gotwant.Test(t, perr.Kind, gli.KindDecodeFailure)
+		gotwant.Test(t, perr.Name, "FILES")
+		gotwant.Test(t, perr.Index, 4)
+	})
+
+	t.Run("MissingValue", func(t *testing.T) {
+		perr := parse("--level")
+		gotwant.Test(t, perr.Kind, gli.KindMissingValue)
+		gotwant.Test(t, perr.Name, "level")
+		gotwant.Test(t, perr.Index, 0)
+		gotwant.Test(t, perr.Error(), `option "level" without arguments`)
+
+		perr = parse("-v", "ls", "--name", "a", "--limit")
+		gotwant.Test(t, perr.Kind, gli.KindMissingValue)
+		gotwant.Test(t, perr.Name, "limit")
+		gotwant.Test(t, perr.Path, []string{"list"})
+		gotwant.Test(t, perr.Index, 4)
+
+		perr = parse("--level", "ls", "--name", "a")
+		gotwant.Test(t, perr.Kind, gli.KindMissingValue)
+		gotwant.Test(t, perr.Name, "level")
+		gotwant.Test(t, perr.Path, []string(nil))
+		gotwant.Test(t, perr.Index, 0)
+
+		perr = parse("--verbose=true")
+		gotwant.Test(t, perr.Kind, gli.KindDecodeFailure)
+		gotwant.Test(t, perr.Name, "verbose")
+		gotwant.Test(t, perr.Index, 0)
+	})
+
+	t.Run("MissingRequired", func(t *testing.T) {
+		perr := parse("ls")
+		gotwant.Test(t, perr.Kind, gli.KindMissingRequired)
+		gotwant.Test(t, perr.Name, "name")
+		gotwant.Test(t, perr.Path, []string{"list"})
+		gotwant.Test(t, perr.Index, -1)
+	})
+}
@@ -0,0 +1,99 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/shu-go/gli/v2"
+	"github.com/shu-go/gotwant"
+)
+
+type groupGlobal struct {
+	Verbose bool   `cli:"v,verbose" help:"verbose output"`
+	Host    string `cli:"host=HOST" help:"host name" group:"Network"`
+	Color   bool   `help:"colorize" group:"Output"`
+	Port    int    `cli:"p,port=PORT" help:"port number" group:"Network"`
+
+	Run   groupSub `help:"run a container"`
+	Image groupSub `help:"manage images" category:"Management Commands"`
+	Ps    groupSub `help:"list containers"`
+	Vol   groupSub `cli:"volume" help:"manage volumes" category:"Management Commands"`
+}
+
+type groupSub struct {
+	Quiet bool `cli:"q" help:"quiet" group:"Output"`
+}
+
+func TestGroup(t *testing.T) {
+	app := newApp(&groupGlobal{})
+	app.Name = "app"
+	app.AddExtraCommand(&groupSub{}, "system", "manage the system", gli.Category("Management Commands"))
+
+	out, err := runWithStdout(t, &app, "help")
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, out, `app
+
+Sub commands:
+  run     run a container
+  ps      list containers
+
+Management Commands:
+  image   manage images
+  volume  manage volumes
+  system  manage the system
+
+Options:
+  -v, --verbose    verbose output
+
+Network:
+  --host HOST      host name
+  -p, --port PORT  port number
+
+Output:
+  --color          colorize
+
+Help sub commands:
+  help     app help subcommnad subsubcommand
+  version  show version
+`)
+
+	t.Run("Inherited", func(t *testing.T) {
+		app := newApp(&groupGlobal{})
+		app.Name = "app"
+
+		out, err := runWithStdout(t, &app, "help", "ps")
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, out, `command ps - list containers
+
+Output:
+  -q  quiet
+
+Global Options:
+  -v, --verbose    verbose output
+  --host HOST      host name
+  --color          colorize
+  -p, --port PORT  port number
+`)
+	})
+
+	t.Run("Template", func(t *testing.T) {
+		app := newApp(&groupGlobal{})
+		app.HelpTemplate = `{{range .SubGroups}}[{{.Title}}]{{range .Subs}} {{.Name}}{{end}}
+{{end}}{{range .OptionGroups}}[{{.Title}}]{{range .Options}} {{index .Names 0}}{{end}}
+{{end}}`
+
+		out, err := runWithStdout(t, &app, "help")
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, out, `[] run ps
+[Management Commands] image volume
+[] -v
+[Network] --host -p
+[Output] --color
+`)
+	})
+
+	t.Run("Info", func(t *testing.T) {
+		app := newApp(&groupGlobal{})
+		gotwant.Test(t, app.Root().Options()[1].Group(), "Network")
+		gotwant.Test(t, app.Root().Commands()[1].Category(), "Management Commands")
+	})
+}
@@ -0,0 +1,237 @@
+package test
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/shu-go/gli/v2"
+	"github.com/shu-go/gotwant"
+)
+
+type helpGlobal struct {
+	File    string `cli:"f,file=FILE" help:"a file" default:"a.txt"`
+	Verbose bool   `cli:"v,verbose" help:"verbose output"`
+
+	List helpList `cli:"ls,list" help:"list items"`
+}
+
+type helpList struct {
+	Done bool     `help:"done items" xor:"state"`
+	Tags []string `args:"rest=TAGS" help:"tags to filter"`
+}
+
+const helpTemplate = `{{.Name}}{{if .Desc}} - {{.Desc}}{{end}}
+{{- if not .IsRoot}} {{join .Command.Path " "}}: {{.Command.Help}}{{end}}
+{{if .Subs}}
+COMMANDS
+{{range .Subs}}  {{pad (join .Names ", ") $.SubWidth}}{{.Help}}
+{{end}}{{end}}{{if .Args}}
+ARGUMENTS
+{{range .Args}}  {{pad .Name $.ArgWidth}}{{.Help}}
+{{end}}{{end}}{{if .Options}}
+OPTIONS
+{{range .Options}}  {{pad .Label $.OptionWidth}}{{.Help}}{{if .Default}} [{{.Default}}]{{end}}{{if .Xor}} (xor {{join .Xor ","}}){{end}}
+{{end}}{{end}}{{range $g := .Inherited}}
+{{upper .Title}}
+{{range .Options}}  {{pad .Label $g.Width}}{{.Help}}
+{{end}}{{end}}{{if .Usage}}
+USAGE
+  {{indent 2 .Usage}}
+{{end}}`
+
+func TestHelpTemplate(t *testing.T) {
+	app := newApp(&helpGlobal{})
+	app.Name = "app"
+	app.Desc = "test app"
+	app.HelpTemplate = helpTemplate
+
+	out, err := runWithStdout(t, &app, "help")
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, out, `app - test app
+
+COMMANDS
+  list, ls  list items
+
+OPTIONS
+  -f, --file FILE  a file [a.txt]
+  -v, --verbose    verbose output
+`)
+
+	app = newApp(&helpGlobal{})
+	app.Name = "app"
+	app.HelpTemplate = helpTemplate
+
+	out, err = runWithStdout(t, &app, "help", "list")
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, out, `app list: list items
+
+ARGUMENTS
+  [TAGS...]  tags to filter
+
+OPTIONS
+  --done  done items (xor state)
+
+GLOBAL OPTIONS
+  -f, --file FILE  a file
+  -v, --verbose    verbose output
+
+USAGE
+  list [options] [TAGS...]
+`)
+
+	t.Run("Invalid", func(t *testing.T) {
+		app := newApp(&helpGlobal{})
+		app.HelpTemplate = "{{.NoSuchField}}"
+
+		out, err := runWithStdout(t, &app, "help")
+		gotwant.TestError(t, err, nil)
+		gotwant.TestExpr(t, out, strings.HasPrefix(out, app.Name)) // built-in
+	})
+}
+
+type wrapGlobal struct {
+	File string `cli:"f,file=FILE" help:"the file name to read the list of items from, one item per line" default:"a.txt"`
+	Jp   bool   `help:"日本語の説明はスペースがなくても表示幅で折り返されます"`
+
+	Sub  wrapSub  `help:"a sub command with a long description to be wrapped" usage:"app sub [options] FILE FILE FILE FILE FILE FILE"`
+	Long struct{} `cli:"長い名前,ln" help:"CJK name"`
+}
+
+type wrapSub struct{}
+
+func TestHelpWrap(t *testing.T) {
+	app := newApp(&wrapGlobal{})
+	app.Name = "app"
+	app.HelpWidth = 40
+
+	out, err := runWithStdout(t, &app, "help", "sub")
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, out, `command sub - a sub command with a long
+              description to be wrapped
+
+Global Options:
+  -f, --file FILE  the file name to read
+                   the list of items
+                   from, one item per
+                   line (default: a.txt)
+  --jp             日本語の説明はスペー
+                   スがなくても表示幅で
+                   折り返されます
+
+Usage:
+  app sub [options] FILE FILE FILE
+    FILE FILE FILE
+`)
+
+	t.Run("SubPadding", func(t *testing.T) {
+		app := newApp(&wrapGlobal{})
+		app.HelpWidth = -1
+		out, _ := runWithStdout(t, &app, "help")
+		gotwant.TestExpr(t, out, strings.Contains(out, "  長い名前, ln  CJK name\n"))
+		gotwant.TestExpr(t, out, strings.Contains(out, "  sub           a sub command"))
+	})
+
+	t.Run("Columns", func(t *testing.T) {
+		os.Setenv("COLUMNS", "40")
+		defer os.Unsetenv("COLUMNS")
+
+		app := newApp(&wrapGlobal{})
+		app.Name = "app"
+		out, _ := runWithStdout(t, &app, "help", "sub")
+		gotwant.TestExpr(t, out, strings.HasPrefix(out, "command sub - a sub command with a long\n"))
+
+		app = newApp(&wrapGlobal{})
+		app.Name = "app"
+		app.HelpWidth = -1
+		out, _ = runWithStdout(t, &app, "help", "sub")
+		gotwant.TestExpr(t, out, strings.HasPrefix(out, "command sub - a sub command with a long description to be wrapped\n"))
+	})
+}
+
+func TestHelpColor(t *testing.T) {
+	theme := &gli.Theme{Header: "1", Option: "32", Placeholder: "33", Default: "2", Error: "31"}
+
+	t.Run("Always", func(t *testing.T) {
+		app := newApp(&helpGlobal{})
+		app.Name = "app"
+		app.Color = gli.ColorAlways
+		app.Theme = theme
+
+		out, err := runWithStdout(t, &app, "help", "list")
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, out, "\x1b[1mcommand\x1b[0m list - list items\n"+
+			"\n"+
+			"\x1b[1mArguments:\x1b[0m\n"+
+			"  \x1b[33m[TAGS...]\x1b[0m  tags to filter\n"+
+			"\n"+
+			"\x1b[1mOptions:\x1b[0m\n"+
+			"  \x1b[32m--done\x1b[0m  done items \x1b[2m(xor: state)\x1b[0m\n"+
+			"\n"+
+			"\x1b[1mGlobal Options:\x1b[0m\n"+
+			"  \x1b[32m-f, --file\x1b[0m \x1b[33mFILE\x1b[0m  a file \x1b[2m(default: a.txt)\x1b[0m\n"+
+			"  \x1b[32m-v, --verbose\x1b[0m    verbose output\n"+
+			"\n"+
+			"\x1b[1mUsage:\x1b[0m\n"+
+			"  list [options] [TAGS...]\n")
+	})
+
+	t.Run("Wrap", func(t *testing.T) {
+		app := newApp(&wrapGlobal{})
+		app.Name = "app"
+		app.HelpWidth = 40
+		app.Color = gli.ColorAlways
+		app.Theme = theme
+
+		out, err := runWithStdout(t, &app, "help", "sub")
+		gotwant.TestError(t, err, nil)
+		plain := regexp.MustCompile("\x1b\\[[0-9;]*m").ReplaceAllString(out, "")
+		gotwant.Test(t, plain, `command sub - a sub command with a long
+              description to be wrapped
+
+Global Options:
+  -f, --file FILE  the file name to read
+                   the list of items
+                   from, one item per
+                   line (default: a.txt)
+  --jp             日本語の説明はスペー
+                   スがなくても表示幅で
+                   折り返されます
+
+Usage:
+  app sub [options] FILE FILE FILE
+    FILE FILE FILE
+`)
+	})
+
+	t.Run("Auto", func(t *testing.T) {
+		// a file is not a terminal
+		app := newApp(&helpGlobal{})
+		app.Color = gli.ColorAuto
+
+		out, err := runWithStdout(t, &app, "help")
+		gotwant.TestError(t, err, nil)
+		gotwant.TestExpr(t, out, !strings.Contains(out, "\x1b"))
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		f, err := os.CreateTemp(t.TempDir(), "stderr")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+
+		app := newApp(&helpGlobal{})
+		app.SuppressErrorOutput = false
+		app.Color = gli.ColorAlways
+		app.Theme = theme
+		app.Stderr = f
+
+		_, err = runWithStdout(t, &app, "--verbose=maybe")
+		gotwant.TestError(t, err, "verbose")
+
+		content, _ := os.ReadFile(f.Name())
+		gotwant.TestExpr(t, string(content), strings.HasPrefix(string(content), "\x1b[31m"))
+	})
+}
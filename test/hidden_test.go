@@ -0,0 +1,118 @@
+package test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shu-go/gli/v2"
+	"github.com/shu-go/gotwant"
+)
+
+type hiddenGlobal struct {
+	Verbose bool `cli:"v,verbose" help:"verbose output"`
+	Debug   bool `help:"debug output" hidden:"true"`
+
+	List  hiddenSub `cli:"ls,list" help:"list items"`
+	Maint hiddenSub `cli:"maint" help:"maintenance" hidden:"true"`
+}
+
+type hiddenSub struct {
+	Trace bool `hidden:"true"`
+}
+
+func TestHidden(t *testing.T) {
+	t.Run("Run", func(t *testing.T) {
+		g := hiddenGlobal{}
+		app := newApp(&g)
+
+		_, err := runWithStdout(t, &app, "--debug", "maint", "--trace")
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, g.Debug, true)
+		gotwant.Test(t, g.Maint.Trace, true)
+	})
+
+	t.Run("Help", func(t *testing.T) {
+		app := newApp(&hiddenGlobal{})
+
+		out, err := runWithStdout(t, &app, "help")
+		gotwant.TestError(t, err, nil)
+		gotwant.TestExpr(t, out, !strings.Contains(out, "debug"), gotwant.Desc(out))
+		gotwant.TestExpr(t, out, !strings.Contains(out, "maint"), gotwant.Desc(out))
+		gotwant.TestExpr(t, out, strings.Contains(out, "list items"), gotwant.Desc(out))
+
+		app = newApp(&hiddenGlobal{})
+		out, err = runWithStdout(t, &app, "help", "maint")
+		gotwant.TestError(t, err, nil)
+		gotwant.TestExpr(t, out, strings.HasPrefix(out, "command maint - maintenance\n"), gotwant.Desc(out))
+		gotwant.TestExpr(t, out, !strings.Contains(out, "trace"), gotwant.Desc(out))
+	})
+
+	t.Run("HelpAll", func(t *testing.T) {
+		app := newApp(&hiddenGlobal{})
+
+		out, err := runWithStdout(t, &app, "help", "--all")
+		gotwant.TestError(t, err, nil)
+		gotwant.TestExpr(t, out, strings.Contains(out, "  --debug        debug output (hidden)\n"), gotwant.Desc(out))
+		gotwant.TestExpr(t, out, strings.Contains(out, "  maint     maintenance (hidden)\n"), gotwant.Desc(out))
+
+		app = newApp(&hiddenGlobal{})
+		out, err = runWithStdout(t, &app, "help", "maint", "--all")
+		gotwant.TestError(t, err, nil)
+		gotwant.TestExpr(t, out, strings.Contains(out, "  --trace   (hidden)\n"), gotwant.Desc(out))
+	})
+
+	t.Run("Extra", func(t *testing.T) {
+		app := newApp(&hiddenGlobal{})
+		app.AddExtraCommand(&hiddenSub{}, "secret", "a secret command", gli.Hidden())
+
+		out, err := runWithStdout(t, &app, "help")
+		gotwant.TestError(t, err, nil)
+		gotwant.TestExpr(t, out, !strings.Contains(out, "secret"), gotwant.Desc(out))
+		gotwant.Test(t, app.Root().Commands()[2].Hidden(), true)
+	})
+
+	t.Run("Completion", func(t *testing.T) {
+		app := newApp(&hiddenGlobal{})
+		out, err := runWithStdout(t, &app, "__complete", "")
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, strings.Fields(out), []string{"ls", "list", "help", "version"})
+
+		app = newApp(&hiddenGlobal{})
+		out, err = runWithStdout(t, &app, "__complete", "--")
+		gotwant.TestError(t, err, nil)
+		gotwant.TestExpr(t, out, !strings.Contains(out, "debug"), gotwant.Desc(out))
+
+		app = newApp(&hiddenGlobal{})
+		out, err = runWithStdout(t, &app, "completion", "bash")
+		gotwant.TestError(t, err, nil)
+		gotwant.TestExpr(t, out, !strings.Contains(out, "maint"), gotwant.Desc(out))
+	})
+
+	t.Run("Docs", func(t *testing.T) {
+		app := newApp(&hiddenGlobal{})
+		app.Name = "app"
+
+		dir := t.TempDir()
+		gotwant.TestError(t, app.WriteMarkdown(dir), nil)
+		_, err := os.Stat(filepath.Join(dir, "app-maint.md"))
+		gotwant.TestExpr(t, err, os.IsNotExist(err))
+
+		content, err := os.ReadFile(filepath.Join(dir, "app.md"))
+		gotwant.TestError(t, err, nil)
+		gotwant.TestExpr(t, string(content), !strings.Contains(string(content), "debug"))
+	})
+
+	t.Run("Suggestions", func(t *testing.T) {
+		app := newApp(&hiddenGlobal{})
+		app.AllowAbbrev = true
+
+		_, err := runWithStdout(t, &app, "--debu")
+		var perr *gli.ParseError
+		gotwant.TestExpr(t, err, errors.As(err, &perr))
+		gotwant.Test(t, perr.Kind, gli.KindUnknownOption)
+		gotwant.Test(t, perr.Suggestions, []string(nil))
+	})
+}
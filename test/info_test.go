@@ -0,0 +1,79 @@
+package test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shu-go/gotwant"
+)
+
+type infoGlobal struct {
+	infoEmbedded
+
+	List infoList `cli:"ls,list" help:"list items" usage:"app list"`
+
+	File string `cli:"f,file=FILE" help:"file name" default:"./todo.json" env:"APP_FILE" config:"storage"`
+}
+
+type infoEmbedded struct {
+	Verbose bool `cli:"v,verbose"`
+}
+
+type infoList struct {
+	Due *time.Time `required:"true" type:"Date"`
+}
+
+type infoExtra struct{}
+
+func TestInfo(t *testing.T) {
+	app := newApp(&infoGlobal{})
+	app.AddExtraCommand(&infoExtra{}, "extra", "an extra command")
+
+	root := app.Root()
+	gotwant.Test(t, root.IsRoot(), true)
+	gotwant.Test(t, root.Name(), "")
+	gotwant.TestExpr(t, root.Parent(), root.Parent() == nil)
+	gotwant.Test(t, root.Type(), reflect.TypeOf(infoGlobal{}))
+
+	opts := root.Options()
+	gotwant.Test(t, len(opts), 2)
+	gotwant.Test(t, opts[0].Name(), "verbose")
+	gotwant.Test(t, opts[0].FieldPath(), []int{0, 0})
+	gotwant.Test(t, opts[0].TakesArg(), false)
+	gotwant.Test(t, opts[1].Names(), []string{"f", "file"})
+	gotwant.Test(t, opts[1].Placeholder(), "FILE")
+	gotwant.Test(t, opts[1].Default(), "./todo.json")
+	gotwant.Test(t, opts[1].Env(), "APP_FILE")
+	gotwant.Test(t, opts[1].ConfigKey(), "storage")
+	gotwant.Test(t, opts[1].FieldPath(), []int{2})
+	gotwant.Test(t, opts[1].Type(), reflect.TypeOf(""))
+	gotwant.Test(t, opts[1].Tag().Get("help"), "file name")
+	gotwant.Test(t, opts[1].Command().IsRoot(), true)
+
+	cmds := root.Commands()
+	gotwant.Test(t, len(cmds), 2)
+
+	list := cmds[0]
+	gotwant.Test(t, list.Name(), "list")
+	gotwant.Test(t, list.Names(), []string{"ls", "list"})
+	gotwant.Test(t, list.Path(), []string{"list"})
+	gotwant.Test(t, list.Help(), "list items")
+	gotwant.Test(t, list.Usage(), "app list")
+	gotwant.Test(t, list.Tag().Get("cli"), "ls,list")
+	gotwant.Test(t, list.FieldPath(), []int{1})
+	gotwant.Test(t, list.IsExtra(), false)
+	gotwant.Test(t, list.Parent().IsRoot(), true)
+	gotwant.Test(t, list.Type(), reflect.TypeOf(infoList{}))
+
+	due := list.Options()[0]
+	gotwant.Test(t, due.Required(), true)
+	gotwant.Test(t, due.DecType(), "Date")
+	gotwant.Test(t, due.Type(), reflect.TypeOf(&time.Time{}))
+	gotwant.Test(t, due.ConfigKey(), "list.due")
+
+	extra := cmds[1]
+	gotwant.Test(t, extra.Name(), "extra")
+	gotwant.Test(t, extra.IsExtra(), true)
+	gotwant.Test(t, extra.FieldPath(), []int(nil))
+}
@@ -0,0 +1,66 @@
+package test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shu-go/gotwant"
+)
+
+type manGlobal struct {
+	List manList `cli:"ls,list" help:"list items" usage:"app list [--done]\n.hidden"`
+
+	File string `cli:"f,file=FILE" help:"file name" default:"./todo.json" env:"APP_FILE"`
+}
+
+type manList struct {
+	Done  bool `help:"only done items" default:"true"`
+	Limit int  `defdesc:"unlimited" required:"true"`
+}
+
+func TestManPage(t *testing.T) {
+	t.Run("Combined", func(t *testing.T) {
+		app := newApp(&manGlobal{})
+		app.Name = "app"
+		app.Desc = "a todo app"
+		app.Version = "1.2"
+		app.Copyright = "(C) someone"
+
+		buf := &bytes.Buffer{}
+		gotwant.TestError(t, app.ManPage(buf), nil)
+		s := buf.String()
+
+		gotwant.TestExpr(t, s, strings.HasPrefix(s, `.TH "APP" "1" "" "app 1.2" "User Commands"`))
+		gotwant.TestExpr(t, s, strings.Contains(s, "app \\- a todo app\n"))
+		gotwant.TestExpr(t, s, strings.Contains(s, `\fB\-f\fR, \fB\-\-file\fR \fIFILE\fR`))
+		gotwant.TestExpr(t, s, strings.Contains(s, "Default: ./todo.json. Environment: APP_FILE."))
+		gotwant.TestExpr(t, s, strings.Contains(s, `.SS "app list"`))
+		gotwant.TestExpr(t, s, strings.Contains(s, "Default: unlimited. Required."))
+		gotwant.TestExpr(t, s, strings.Contains(s, `\fB\-\-no\-done\fR to disable.`))
+		gotwant.TestExpr(t, s, strings.Contains(s, "\\&.hidden\n"))
+		gotwant.TestExpr(t, s, strings.Contains(s, ".SH COPYRIGHT\n(C) someone\n"))
+	})
+
+	t.Run("PerCommand", func(t *testing.T) {
+		app := newApp(&manGlobal{})
+		app.Name = "app"
+
+		dir := t.TempDir()
+		paths, err := app.ManPages(dir)
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, paths, []string{filepath.Join(dir, "app.1"), filepath.Join(dir, "app-list.1")})
+
+		content, _ := os.ReadFile(filepath.Join(dir, "app-list.1"))
+		s := string(content)
+		gotwant.TestExpr(t, s, strings.Contains(s, "app\\-list \\- list items\n"))
+		gotwant.TestExpr(t, s, strings.Contains(s, ".SH GLOBAL OPTIONS\n"))
+		gotwant.TestExpr(t, s, strings.Contains(s, ".SH SEE ALSO\n\\fBapp\\fR(1)\n"))
+
+		content, _ = os.ReadFile(filepath.Join(dir, "app.1"))
+		s = string(content)
+		gotwant.TestExpr(t, s, strings.Contains(s, "See \\fBapp\\-list\\fR(1)."))
+	})
+}
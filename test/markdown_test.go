@@ -0,0 +1,75 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shu-go/gotwant"
+)
+
+type mdGlobal struct {
+	List mdList `cli:"ls,list" help:"list items" usage:"app list [--done]"`
+	Get  mdGet  `help:"get items"`
+
+	File string `cli:"f,file=FILE" help:"file name" default:"./todo.json" env:"APP_FILE"`
+}
+
+type mdList struct {
+	Done  bool   `help:"only done | items" default:"true"`
+	Limit int    `required:"true"`
+	Sub   mdSub2 `help:"sub sub"`
+}
+
+type mdSub2 struct{}
+
+type mdGet struct {
+	_ struct{} `args:"1..3"`
+
+	ID   string   `arg:"0" required:"true" help:"item | id"`
+	Keys []string `args:"rest=KEY" help:"keys"`
+}
+
+func TestMarkdown(t *testing.T) {
+	app := newApp(&mdGlobal{})
+	app.Name = "app"
+	app.Desc = "a todo app"
+	app.Usage = "app [options] command"
+	app.Copyright = "(C) someone"
+
+	dir := t.TempDir()
+	gotwant.TestError(t, app.WriteMarkdown(dir), nil)
+
+	content, err := os.ReadFile(filepath.Join(dir, "app.md"))
+	gotwant.TestError(t, err, nil)
+	s := string(content)
+	gotwant.TestExpr(t, s, strings.HasPrefix(s, "# app\n\na todo app\n"))
+	gotwant.TestExpr(t, s, strings.Contains(s, "## Usage\n\n```\napp [options] command\n```\n"))
+	gotwant.TestExpr(t, s, strings.Contains(s, "| [list](app-list.md) | `ls` | list items |"))
+	gotwant.TestExpr(t, s, strings.Contains(s, "| `-f`, `--file` | FILE | `./todo.json` | `APP_FILE` |  | file name |"))
+	gotwant.TestExpr(t, s, strings.HasSuffix(s, "(C) someone\n"))
+
+	content, err = os.ReadFile(filepath.Join(dir, "app-list.md"))
+	gotwant.TestError(t, err, nil)
+	s = string(content)
+	gotwant.TestExpr(t, s, strings.HasPrefix(s, "# app list\n\nlist items\n\nAliases: `ls`, `list`\n\nParent: [app](app.md)\n"))
+	gotwant.TestExpr(t, s, strings.Contains(s, "| `--done`, `--no-done` |  | `true` |  |  | only done \\| items |"))
+	gotwant.TestExpr(t, s, strings.Contains(s, "| `--limit` |  |  |  | yes |  |"))
+	gotwant.TestExpr(t, s, strings.Contains(s, "## Global Options"))
+	gotwant.TestExpr(t, s, strings.Contains(s, "| [sub](app-list-sub.md) |  | sub sub |"))
+
+	content, err = os.ReadFile(filepath.Join(dir, "app-list-sub.md"))
+	gotwant.TestError(t, err, nil)
+	s = string(content)
+	gotwant.TestExpr(t, s, strings.Contains(s, "Parent: [app list](app-list.md)"))
+	gotwant.TestExpr(t, s, strings.Contains(s, "## Options of list"))
+	gotwant.TestExpr(t, s, strings.Contains(s, "## Usage\n\n```\napp list sub [ARGS...]\n```\n"), gotwant.Desc(s))
+
+	content, err = os.ReadFile(filepath.Join(dir, "app-get.md"))
+	gotwant.TestError(t, err, nil)
+	s = string(content)
+	gotwant.TestExpr(t, s, strings.Contains(s, "## Usage\n\n```\napp get ID [KEY...]\n```\n"), gotwant.Desc(s))
+	gotwant.TestExpr(t, s, strings.Contains(s, "## Arguments\n\nNumber of arguments: 1 to 3.\n\n| Argument | Required | Description |\n"), gotwant.Desc(s))
+	gotwant.TestExpr(t, s, strings.Contains(s, "| `ID` | yes | item \\| id |\n| `KEY...` |  | keys |\n"), gotwant.Desc(s))
+}
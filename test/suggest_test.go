@@ -0,0 +1,110 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shu-go/gli/v2"
+	"github.com/shu-go/gotwant"
+)
+
+type suggestGlobal struct {
+	Verbose bool `cli:"v,verbose"`
+	Force   bool `default:"true"`
+
+	List   suggestList `cli:"ls,list"`
+	Remove struct{}    `cli:"rm,remove"`
+}
+
+type suggestList struct {
+	Limit  int
+	Format string
+}
+
+func (suggestList) Run() {}
+
+func suggestionsOf(t *testing.T, app *gli.App, args ...string) (gli.ParseErrorKind, []string) {
+	t.Helper()
+
+	err := app.Run(args)
+	var perr *gli.ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("%v is not a ParseError", err)
+	}
+	return perr.Kind, perr.Suggestions
+}
+
+func TestSuggestions(t *testing.T) {
+	t.Run("Option", func(t *testing.T) {
+		app := newApp(&suggestGlobal{})
+		kind, sugg := suggestionsOf(t, &app, "--verbsoe")
+		gotwant.Test(t, kind, gli.KindUnknownOption)
+		gotwant.Test(t, sugg, []string{"verbose"})
+
+		app = newApp(&suggestGlobal{})
+		_, sugg = suggestionsOf(t, &app, "--verb")
+		gotwant.Test(t, sugg, []string{"verbose"})
+
+		app = newApp(&suggestGlobal{})
+		_, sugg = suggestionsOf(t, &app, "--no-forse")
+		gotwant.Test(t, sugg, []string{"no-force"})
+
+		app = newApp(&suggestGlobal{})
+		_, sugg = suggestionsOf(t, &app, "--xyz")
+		gotwant.Test(t, sugg, []string(nil))
+	})
+
+	t.Run("AncestorOption", func(t *testing.T) {
+		app := newApp(&suggestGlobal{})
+		_, sugg := suggestionsOf(t, &app, "ls", "--fromat", "x")
+		gotwant.Test(t, sugg, []string{"format"})
+
+		app = newApp(&suggestGlobal{})
+		_, sugg = suggestionsOf(t, &app, "ls", "--verbos")
+		gotwant.Test(t, sugg, []string{"verbose"})
+	})
+
+	t.Run("Command", func(t *testing.T) {
+		app := newApp(&suggestGlobal{})
+		app.StrictCommands = true
+		kind, sugg := suggestionsOf(t, &app, "lsit")
+		gotwant.Test(t, kind, gli.KindUnknownCommand)
+		gotwant.Test(t, sugg, []string{"list"})
+
+		app = newApp(&suggestGlobal{})
+		app.StrictCommands = true
+		app.AddExtraCommand(&struct{}{}, "status", "")
+		_, sugg = suggestionsOf(t, &app, "stats")
+		gotwant.Test(t, sugg, []string{"status"})
+
+		// not strict: help with suggestions
+		app = newApp(&suggestGlobal{})
+		errout, err := runWithStderr(t, &app, "lsit")
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, errout, "command \"lsit\" not defined\n\n    maybe [list] ?\n\n")
+	})
+
+	t.Run("Stderr", func(t *testing.T) {
+		app := newApp(&suggestGlobal{})
+		errout, err := runWithStderr(t, &app, "--verbsoe")
+		gotwant.TestError(t, err, gli.ErrNotDefined)
+		gotwant.Test(t, errout, "option \"verbsoe\" not defined\n\n    maybe [verbose] ?\n\n")
+	})
+
+	t.Run("Threshold", func(t *testing.T) {
+		app := newApp(&suggestGlobal{})
+		app.SuggestDistance = 0
+		_, sugg := suggestionsOf(t, &app, "--verbsoe")
+		gotwant.Test(t, sugg, []string(nil))
+
+		app = newApp(&suggestGlobal{})
+		app.SuggestDistance = 1
+		_, sugg = suggestionsOf(t, &app, "--vrebsoe")
+		gotwant.Test(t, sugg, []string(nil))
+
+		app = newApp(&suggestGlobal{})
+		app.SuggestDistance = 3
+		_, sugg = suggestionsOf(t, &app, "--vrebsoe")
+		gotwant.Test(t, sugg, []string{"verbose"})
+	})
+}
@@ -2,6 +2,7 @@ package test
 
 import (
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/shu-go/gli/v2"
@@ -220,3 +221,119 @@ func TestRequiredNested(t *testing.T) {
 	gotwant.TestError(t, err, nil)
 	gotwant.Test(t, g, wantg)
 }
+
+func TestXor(t *testing.T) {
+	type global struct {
+		Done   bool   `xor:"state"`
+		Undone bool   `xor:"state"`
+		All    bool   `xor:"state,filter" default:"true"`
+		Query  string `xor:"filter"`
+	}
+
+	app := newApp(&global{})
+	err := app.Run([]string{"--done"})
+	gotwant.TestError(t, err, nil)
+
+	app = newApp(&global{})
+	err = app.Run([]string{"--done", "--undone"})
+	gotwant.TestError(t, err, "option done and option undone are mutually exclusive")
+
+	// default tags do not count
+	app = newApp(&global{})
+	err = app.Run([]string{"--query", "abc"})
+	gotwant.TestError(t, err, nil)
+
+	app = newApp(&global{})
+	err = app.Run([]string{"--all", "--query", "abc"})
+	gotwant.TestError(t, err, "option all and option query are mutually exclusive")
+
+	// neither do config nor env
+	os.Setenv("XOR_UNDONE", "true")
+	type envGlobal struct {
+		Done   bool `xor:"state"`
+		Undone bool `xor:"state" env:"XOR_UNDONE"`
+	}
+	app = newApp(&envGlobal{})
+	err = app.Run([]string{"--done"})
+	gotwant.TestError(t, err, nil)
+	os.Setenv("XOR_UNDONE", "")
+
+	app = newApp(&envGlobal{})
+	err = app.LoadConfig(strings.NewReader("done = true"), "ini")
+	gotwant.TestError(t, err, nil)
+	err = app.Run([]string{"--undone"})
+	gotwant.TestError(t, err, nil)
+}
+
+func TestRules(t *testing.T) {
+	type fetchCmd struct {
+		_ struct{} `requiresany:"id,name"`
+
+		ID   int
+		Name string
+		All  bool `conflicts:"@args,id"`
+	}
+	type global struct {
+		Key   string `requires:"cert"`
+		Cert  string
+		Proxy string `requiresany:"user,token"`
+		User  string
+		Token string `default:"anonymous"`
+
+		Fetch fetchCmd
+	}
+
+	cases := []struct {
+		args []string
+		err  interface{}
+	}{
+		{[]string{}, nil},
+		{[]string{"--key", "k", "--cert", "c"}, nil},
+		{[]string{"--key", "k"}, "option key requires option cert"},
+		{[]string{"--proxy", "p"}, nil}, // token has a default value
+		{[]string{"fetch", "--name", "n"}, nil},
+		{[]string{"--key", "k", "fetch", "--id", "1"}, "option key requires option cert"},
+		{[]string{"fetch"}, "one of options id, name is required"},
+		{[]string{"fetch", "--name", "n", "--all"}, nil},
+		{[]string{"fetch", "--name", "n", "--all", "arg"}, "option all conflicts with arguments"},
+		{[]string{"fetch", "--id", "1", "--all"}, "option all conflicts with option id"},
+	}
+	for _, c := range cases {
+		app := newApp(&global{})
+		err := app.Run(c.args)
+		gotwant.TestError(t, err, c.err, gotwant.Desc(strings.Join(c.args, " ")))
+	}
+
+	// names are checked on Bind (RequiresTag is renamed to needs)
+	for _, c := range []struct {
+		st  interface{}
+		err string
+	}{
+		{&struct {
+			A bool `needs:"b"`
+		}{}, "option b in needs tag of option a"},
+		{&struct {
+			A bool `conflicts:"@args,b"`
+		}{}, "option b in conflicts tag of option a"},
+		{&struct {
+			_   struct{} `requiresany:"a"`
+			A   bool
+			Sub struct {
+				C bool `needs:"a,d"`
+			}
+		}{}, "option d in needs tag of option c"},
+	} {
+		app := gli.New()
+		app.RequiresTag = "needs"
+		err := app.Bind(c.st)
+		gotwant.TestError(t, err, gli.ErrNotDefined)
+		gotwant.TestError(t, err, c.err)
+	}
+
+	// config values do not conflict
+	app := newApp(&global{})
+	err := app.LoadConfig(strings.NewReader(`{"fetch": {"all": true}}`), "json")
+	gotwant.TestError(t, err, nil)
+	err = app.Run([]string{"fetch", "--id", "1"})
+	gotwant.TestError(t, err, nil)
+}
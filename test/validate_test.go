@@ -0,0 +1,237 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shu-go/gli/v2"
+	"github.com/shu-go/gotwant"
+)
+
+type validateGlobal struct {
+	Level   int               `min:"1" max:"5" default:"1"`
+	Ratio   float64           `min:"0" max:"1"`
+	Timeout time.Duration     `max:"1m"`
+	Name    string            `len:"2..4" pattern:"^[a-z]+$"`
+	Color   string            `oneof:"red, green, blue"`
+	Tags    []string          `max:"2" oneof:"a,b,c"`
+	Labels  map[string]string `min:"1" pattern:"^[0-9]+$"`
+	Count   *uint             `max:"10"`
+	Hosts   []string          `min:"2"`
+	Pairs   map[string]string `len:"2"`
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		args []string
+		err  interface{}
+	}{
+		{[]string{}, nil},
+		{[]string{"--level", "5", "--ratio", "0.5", "--timeout", "30s"}, nil},
+		{[]string{"--level", "0"}, "option level: 0 is less than min 1"},
+		{[]string{"--level", "6"}, "option level: 6 is greater than max 5"},
+		{[]string{"--ratio", "1.5"}, "option ratio: 1.5 is greater than max 1"},
+		{[]string{"--timeout", "2m"}, "option timeout: 2m0s is greater than max 1m"},
+		{[]string{"--name", "abc"}, nil},
+		{[]string{"--name", "a"}, "option name: length 1 is not 2..4"},
+		{[]string{"--name", "ABC"}, `option name: "ABC" does not match pattern ^[a-z]+$`},
+		{[]string{"--color", "green"}, nil},
+		{[]string{"--color", "pink"}, `option color: "pink" is not one of red, green, blue`},
+		{[]string{"--tags", "a,b"}, nil},
+		{[]string{"--tags", "a,b,c"}, "option tags: length 3 is greater than max 2"},
+		{[]string{"--tags", "a", "--tags", "d"}, `option tags: "d" is not one of a, b, c`},
+		{[]string{"--labels", "x:1"}, nil},
+		{[]string{"--labels", "x:y"}, `option labels: "y" does not match pattern ^[0-9]+$`},
+		{[]string{"--count", "10"}, nil},
+		{[]string{"--count", "11"}, "option count: 11 is greater than max 10"},
+		{[]string{"--hosts", "a", "--hosts", "b"}, nil},
+		{[]string{"--hosts", "a"}, "option hosts: length 1 is less than min 2"},
+		{[]string{"--pairs", "a:1", "--pairs", "b:2"}, nil},
+		{[]string{"--pairs", "a:1,b:2,c:3"}, "option pairs: length 3 is not 2"},
+	}
+	for _, c := range cases {
+		app := newApp(&validateGlobal{})
+		err := app.Run(c.args)
+		gotwant.TestError(t, err, c.err, gotwant.Desc(strings.Join(c.args, " ")))
+	}
+
+	t.Run("ErrorsAs", func(t *testing.T) {
+		app := newApp(&validateGlobal{})
+		err := app.Run([]string{"--level", "9"})
+		var verr *gli.ValidationError
+		gotwant.Test(t, errors.As(err, &verr), true)
+		gotwant.Test(t, verr.Tag, "max")
+		gotwant.Test(t, verr.Value, "9")
+	})
+
+	t.Run("DefaultAndEnv", func(t *testing.T) {
+		type global struct {
+			Level int `min:"1" default:"0"`
+		}
+		app := newApp(&global{})
+		err := app.Run([]string{"--level", "3"})
+		gotwant.TestError(t, err, "default of option level: 0 is less than min 1")
+
+		type envGlobal struct {
+			Level int `min:"1" env:"VALIDATE_LEVEL"`
+		}
+		os.Setenv("VALIDATE_LEVEL", "0")
+		app = newApp(&envGlobal{})
+		err = app.Run([]string{})
+		gotwant.TestError(t, err, "env VALIDATE_LEVEL: 0 is less than min 1")
+
+		// help, version and completion still work
+		for _, args := range [][]string{{"help"}, {"version"}, {"__complete", "--l"}} {
+			app = newApp(&envGlobal{})
+			out, err := runWithStdout(t, &app, args...)
+			gotwant.TestError(t, err, nil, gotwant.Desc(strings.Join(args, " ")))
+			gotwant.TestExpr(t, out, args[0] == "version" || strings.Contains(out, "level"), gotwant.Desc(out))
+		}
+		os.Setenv("VALIDATE_LEVEL", "")
+
+		type lenGlobal struct {
+			Hosts []string `min:"2" default:"a" env:"VALIDATE_HOSTS"`
+		}
+		app = newApp(&lenGlobal{})
+		err = app.Run([]string{})
+		gotwant.TestError(t, err, "option hosts: length 1 is less than min 2")
+
+		g := lenGlobal{}
+		app = newApp(&g)
+		err = app.Run([]string{"--hosts", "x", "--hosts", "y"})
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, g.Hosts, []string{"x", "y"})
+
+		os.Setenv("VALIDATE_HOSTS", "a,b")
+		app = newApp(&lenGlobal{})
+		err = app.Run([]string{})
+		gotwant.TestError(t, err, nil)
+		os.Setenv("VALIDATE_HOSTS", "")
+	})
+
+	t.Run("InvalidTags", func(t *testing.T) {
+		for _, c := range []struct {
+			st  interface{}
+			err string
+		}{
+			{&struct {
+				Level int `min:"one"`
+			}{}, `field Level: min: invalid number "one"`},
+			{&struct {
+				Timeout time.Duration `max:"1x"`
+			}{}, `field Timeout: max: invalid number "1x"`},
+			{&struct {
+				Tags []string `max:"two"`
+			}{}, `field Tags: max: invalid length "two"`},
+			{&struct {
+				Name string `len:"2..1"`
+			}{}, `field Name: len: invalid range "2..1"`},
+			{&struct {
+				Name string `pattern:"[a-"`
+			}{}, "field Name: pattern: error parsing regexp"},
+			{&struct {
+				Port int `arg:"0" min:"x"`
+			}{}, `field Port: min: invalid number "x"`},
+		} {
+			app := gli.New()
+			err := app.Bind(c.st)
+			gotwant.TestError(t, err, c.err)
+		}
+	})
+
+	t.Run("TagKeys", func(t *testing.T) {
+		type global struct {
+			Level int      `gte:"1" min:"x"`
+			Color string   `enum:"red,blue"`
+			Tags  []string `size:"2"`
+		}
+		newTagApp := func() gli.App {
+			app := gli.New()
+			app.MinTag = "gte"
+			app.LenTag = "size"
+			app.OneOfTag = "enum"
+			app.SuppressErrorOutput = true
+			gotwant.TestError(t, app.Bind(&global{}), nil)
+			return app
+		}
+
+		app := newTagApp()
+		err := app.Run([]string{"--level", "0"})
+		gotwant.TestError(t, err, "option level: 0 is less than min 1")
+		var verr *gli.ValidationError
+		gotwant.Test(t, errors.As(err, &verr), true)
+		gotwant.Test(t, verr.Tag, "gte")
+
+		app = newTagApp()
+		err = app.Run([]string{"--color", "green"})
+		gotwant.TestError(t, err, `option color: "green" is not one of red, blue`)
+
+		app = newTagApp()
+		err = app.Run([]string{"--tags", "a"})
+		gotwant.TestError(t, err, "option tags: length 1 is not 2")
+	})
+
+	t.Run("Args", func(t *testing.T) {
+		type global struct {
+			Port  int      `arg:"0" min:"1" max:"65535"`
+			Hosts []string `args:"rest" pattern:"^[a-z.]+$"`
+		}
+		app := newApp(&global{})
+		err := app.Run([]string{"0"})
+		gotwant.TestError(t, err, "argument PORT: 0 is less than min 1")
+
+		app = newApp(&global{})
+		err = app.Run([]string{"80", "example.com", "EXAMPLE"})
+		gotwant.TestError(t, err, "argument HOSTS")
+	})
+}
+
+type evenInt int
+
+func (i evenInt) Validate() error {
+	if i%2 != 0 {
+		return fmt.Errorf("%d is odd", i)
+	}
+	return nil
+}
+
+type validGlobal struct {
+	N1 evenInt
+	N2 *evenInt
+
+	Copy validCopy
+}
+
+type validCopy struct {
+	Src string
+	Dst string
+}
+
+func (c validCopy) Validate() error {
+	if c.Src != "" && c.Src == c.Dst {
+		return errors.New("src and dst are the same")
+	}
+	return nil
+}
+
+func TestValidator(t *testing.T) {
+	app := newApp(&validGlobal{})
+	err := app.Run([]string{"--n1", "2", "--n2", "4", "copy", "--src", "a", "--dst", "b"})
+	gotwant.TestError(t, err, nil)
+
+	app = newApp(&validGlobal{})
+	err = app.Run([]string{"--n1", "1", "--n2", "3", "copy", "--src", "a", "--dst", "a"})
+	var merr gli.MultiError
+	gotwant.Test(t, errors.As(err, &merr), true)
+	gotwant.Test(t, len(merr), 3)
+	gotwant.Test(t, err.Error(), "option n1: 1 is odd\noption n2: 3 is odd\ncommand copy: src and dst are the same")
+
+	// not assigned
+	app = newApp(&validGlobal{})
+	err = app.Run([]string{"copy"})
+	gotwant.TestError(t, err, nil)
+}
@@ -42,6 +42,7 @@ import (
 //   - []int (--opt 1,2,3)
 //   - map[string]string (--opt key:value,key:value)
 //   - gli.Range{Min,Max string} (--opt min:max)
+//   - gli.Count (-vvv or --opt=3)
 //
 // # User defined types
 //
@@ -73,6 +74,16 @@ type Range struct {
 	Min, Max string
 }
 
+// Count is a counter option, incremented on each occurrence.
+//
+//	type MyCommand struct {
+//	  Verbose1 gli.Count `cli:"v,verbose"`
+//	  Verbose2 int       `cli:"d,debug" type:"Count"`
+//	}
+//
+//	-vvv, -v -v -v, --verbose=3  ->  Verbose1 == 3
+type Count int
+
 //	type MyCommand struct {
 //	  Separator1 gli.Separator
 //	  Separator2 string `type:"Separator"`
@@ -139,6 +150,9 @@ func strSliceDecoder(s string, v reflect.Value, tag reflect.StructTag, firstTime
 		v.Set(reflect.MakeSlice(v.Type(), 0, 0))
 	}
 	slist := commaRE.FindAllString(s, -1)
+	for i := range slist {
+		slist[i] = strings.ReplaceAll(slist[i], `\,`, `,`)
+	}
 	v.Set(reflect.AppendSlice(v, reflect.ValueOf(slist)))
 	return nil
 }
@@ -200,6 +214,15 @@ func strRangeDecoder(s string, v reflect.Value, tag reflect.StructTag, firstTime
 	return nil
 }
 
+func countDecoder(s string, v reflect.Value, tag reflect.StructTag, firstTime bool) error {
+	n, err := strconv.ParseInt(s, 10, int(v.Type().Size())*8)
+	if err != nil {
+		return err
+	}
+	v.SetInt(n)
+	return nil
+}
+
 func separatorRuneDecoder(s string, v reflect.Value, tag reflect.StructTag, firstTime bool) error {
 	r := rune(' ')
 	switch s {
@@ -270,6 +293,8 @@ func init() {
 	RegisterTypeDecoder(reflect.TypeOf(map[string]string{}), strMapDecoder)
 
 	RegisterTypeDecoder(reflect.TypeOf(Range{}), strRangeDecoder)
+	RegisterTypeDecoder(reflect.TypeOf(Count(0)), countDecoder)
+	RegisterTypeDecoder("Count", countDecoder)
 	RegisterTypeDecoder(reflect.TypeOf(SeparatorRune(0)), separatorRuneDecoder)
 	RegisterTypeDecoder("SeparatorRune", separatorRuneDecoder)
 	RegisterTypeDecoder(reflect.TypeOf(Separator("")), separatorDecoder)
@@ -0,0 +1,437 @@
+package gli
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// ValidationError is returned when an option value violates a validation tag.
+//
+//	Level int      `min:"1" max:"5"`
+//	Name  string   `len:"1..8" pattern:"^[a-z]+$"`
+//	Tags  []string `max:"3" oneof:"red,green,blue"`
+//
+// For numbers, min and max limit the value.
+// For strings, slices and maps, min, max and len (N or N..M) limit the length.
+// pattern (regexp) and oneof (comma separated) apply to the value,
+// or to each element of slices and each value of maps.
+//
+// Invalid tag values (like `min:"one"`) are reported by Bind.
+type ValidationError struct {
+	// Tag is the tag key: min, max, len, pattern or oneof (App.MinTag and so on).
+	Tag string
+	// Value is the violating value (or its length).
+	Value string
+
+	msg string
+}
+
+func (e *ValidationError) Error() string {
+	return e.msg
+}
+
+func validationErrorf(tag string, value interface{}, format string, a ...interface{}) error {
+	return &ValidationError{
+		Tag:   tag,
+		Value: fmt.Sprint(value),
+		msg:   fmt.Sprintf(format, a...),
+	}
+}
+
+// validation is validation tags of an option or an argument, parsed on Bind.
+type validation struct {
+	min, max *limit // a number, or a length
+	len      *limit // a range of lengths
+
+	pattern    *regexp.Regexp
+	patternKey string
+
+	oneof    []string
+	oneofKey string
+}
+
+// limit is a parsed value of a min, max or len tag.
+type limit struct {
+	key   string // tag key
+	value string // tag value
+
+	i int64   // ints, time.Duration and lengths
+	u uint64  // uints
+	f float64 // floats
+	a *arity  // len
+}
+
+// parseValidation parses validation tags for values of t.
+// It returns nil if no validation tags are given.
+func (g App) parseValidation(t reflect.Type, tag reflect.StructTag) (*validation, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var v validation
+	var given bool
+	var err error
+
+	for _, l := range []struct {
+		key string
+		dst **limit
+	}{{g.MinTag, &v.min}, {g.MaxTag, &v.max}, {g.LenTag, &v.len}} {
+		tv, ok := tag.Lookup(l.key)
+		if !ok || l.key == "" {
+			continue
+		}
+		given = true
+
+		lim := &limit{key: l.key, value: strings.TrimSpace(tv)}
+		if l.key == g.LenTag {
+			if lim.a, err = parseArity(tv); err != nil {
+				return nil, fmt.Errorf("%s: invalid range %q", l.key, tv)
+			}
+		} else if err = lim.parse(t); err != nil {
+			return nil, err
+		}
+		*l.dst = lim
+	}
+
+	if tv, ok := tag.Lookup(g.PatternTag); ok && g.PatternTag != "" {
+		given = true
+		if v.pattern, err = regexp.Compile(tv); err != nil {
+			return nil, fmt.Errorf("%s: %v", g.PatternTag, err)
+		}
+		v.patternKey = g.PatternTag
+	}
+
+	if tv, ok := tag.Lookup(g.OneOfTag); ok && g.OneOfTag != "" {
+		given = true
+		v.oneof = splitTagList(tv)
+		v.oneofKey = g.OneOfTag
+	}
+
+	if !given {
+		return nil, nil
+	}
+	return &v, nil
+}
+
+// parse parses l.value as a number of the kind of t, or a length.
+func (l *limit) parse(t reflect.Type) error {
+	var err error
+
+	switch {
+	case t == reflect.TypeOf(time.Duration(0)):
+		var d time.Duration
+		d, err = time.ParseDuration(l.value)
+		l.i = int64(d)
+
+	case isIntKind(t.Kind()):
+		l.i, err = strconv.ParseInt(l.value, 10, 64)
+
+	case isUintKind(t.Kind()):
+		l.u, err = strconv.ParseUint(l.value, 10, 64)
+
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		l.f, err = strconv.ParseFloat(l.value, 64)
+
+	case t.Kind() == reflect.String || isLenKind(t.Kind()):
+		var n int
+		if n, err = strconv.Atoi(l.value); err != nil {
+			return fmt.Errorf("%s: invalid length %q (%v)", l.key, l.value, err)
+		}
+		l.i = int64(n)
+
+	default:
+		// not validated
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s: invalid number %q (%v)", l.key, l.value, err)
+	}
+	return nil
+}
+
+func isIntKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func isUintKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// isLenKind reports whether values of k are validated by their lengths after parsing.
+func isLenKind(k reflect.Kind) bool {
+	return k == reflect.Slice || k == reflect.Array || k == reflect.Map
+}
+
+// validateOptValue checks a decoded value against validation tags.
+// Lengths of slices and maps are not checked here, since they grow with each occurrence of the option.
+// They are checked by validateOptLen after all values are set.
+func validateOptValue(v reflect.Value, vld *validation) error {
+	if vld == nil {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if err := validateRange(v, vld); err != nil {
+			return err
+		}
+		return validateElem(v, vld)
+
+	case reflect.String:
+		if err := validateLen(utf8.RuneCountInString(v.String()), vld); err != nil {
+			return err
+		}
+		return validateElem(v, vld)
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := validateElem(v.Index(i), vld); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := validateElem(iter.Value(), vld); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateOptLen checks min, max and len of a slice or a map.
+// Other values are checked by validateOptValue.
+func validateOptLen(v reflect.Value, vld *validation) error {
+	if vld == nil {
+		return nil
+	}
+
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	if isLenKind(v.Kind()) {
+		return validateLen(v.Len(), vld)
+	}
+	return nil
+}
+
+// errorIfLenBroken reports slices and maps with lengths out of min, max and len tags.
+// It is called after all values (default, config, env and the command line) are set.
+func errorIfLenBroken(cmdStack []*command) error {
+	for i := len(cmdStack) - 1; i >= 0; i-- {
+		c := cmdStack[i]
+		for _, o := range c.options {
+			if !o.assigned {
+				continue
+			}
+
+			fv := o.ownerV.Elem().FieldByIndex(o.fieldIdx)
+			if err := validateOptLen(fv, o.validation); err != nil {
+				return newParseError(KindInvalidValue, o.longestName(), c, -1, errors.Wrap(err, "option "+o.longestName()))
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateRange checks min and max of a number.
+func validateRange(v reflect.Value, vld *validation) error {
+	if l := vld.min; l != nil && compareNumber(v, l) < 0 {
+		return validationErrorf(l.key, v.Interface(), "%v is less than min %s", v.Interface(), l.value)
+	}
+	if l := vld.max; l != nil && compareNumber(v, l) > 0 {
+		return validationErrorf(l.key, v.Interface(), "%v is greater than max %s", v.Interface(), l.value)
+	}
+
+	return nil
+}
+
+// compareNumber compares v with l, returning -1, 0 or +1.
+func compareNumber(v reflect.Value, l *limit) int {
+	switch {
+	case isIntKind(v.Kind()):
+		return compareInt(v.Int(), l.i)
+
+	case isUintKind(v.Kind()):
+		switch {
+		case v.Uint() < l.u:
+			return -1
+		case v.Uint() > l.u:
+			return 1
+		}
+		return 0
+
+	default:
+		switch {
+		case v.Float() < l.f:
+			return -1
+		case v.Float() > l.f:
+			return 1
+		}
+		return 0
+	}
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// validateLen checks min, max and len of a length.
+func validateLen(n int, vld *validation) error {
+	if l := vld.len; l != nil {
+		if n < l.a.min || l.a.max >= 0 && n > l.a.max {
+			return validationErrorf(l.key, n, "length %d is not %s", n, l.value)
+		}
+	}
+
+	if l := vld.min; l != nil && int64(n) < l.i {
+		return validationErrorf(l.key, n, "length %d is less than min %d", n, l.i)
+	}
+	if l := vld.max; l != nil && int64(n) > l.i {
+		return validationErrorf(l.key, n, "length %d is greater than max %d", n, l.i)
+	}
+
+	return nil
+}
+
+// validateElem checks pattern and oneof of a value.
+func validateElem(v reflect.Value, vld *validation) error {
+	s := fmt.Sprint(v.Interface())
+
+	if vld.pattern != nil && !vld.pattern.MatchString(s) {
+		return validationErrorf(vld.patternKey, s, "%q does not match pattern %s", s, vld.pattern)
+	}
+
+	if vld.oneofKey != "" {
+		for _, o := range vld.oneof {
+			if o == s {
+				return nil
+			}
+		}
+		return validationErrorf(vld.oneofKey, s, "%q is not one of %s", s, strings.Join(vld.oneof, ", "))
+	}
+
+	return nil
+}
+
+// Validator is implemented by option value types and command structs.
+//
+// After parsing and before Before hooks, Validate of
+// every assigned option value and every command in the stack is called.
+// All failures are collected into a MultiError.
+//
+//	func (r rangeOpt) Validate() error {
+//	    if r.Min > r.Max {
+//	        return errors.New("min must not exceed max")
+//	    }
+//	    return nil
+//	}
+//
+//	func (c copyCmd) Validate() error {
+//	    if c.Src == c.Dst {
+//	        return errors.New("src and dst are the same")
+//	    }
+//	    return nil
+//	}
+type Validator interface {
+	Validate() error
+}
+
+// MultiError is a list of errors.
+type MultiError []error
+
+func (e MultiError) Error() string {
+	var msgs []string
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the errors for errors.Is and errors.As (Go 1.20 or later).
+func (e MultiError) Unwrap() []error {
+	return []error(e)
+}
+
+// callValidators calls Validate of options and commands in cmdStack, root first.
+func callValidators(cmdStack []*command) error {
+	var errs MultiError
+
+	for _, c := range cmdStack {
+		for _, o := range c.options {
+			if !o.assigned {
+				continue
+			}
+
+			fv := o.ownerV.Elem().FieldByIndex(o.fieldIdx)
+			if err := callValidator(fv); err != nil {
+				errs = append(errs, errors.Wrap(err, "option "+o.longestName()))
+			}
+		}
+
+		if err := callValidator(c.selfV); err != nil {
+			if c.parent == nil {
+				errs = append(errs, err)
+			} else {
+				errs = append(errs, errors.Wrap(err, "command "+strings.Join(c.longestNameStack(), " ")))
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// callValidator calls Validate of v or &v, if implemented.
+func callValidator(v reflect.Value) error {
+	if !v.IsValid() || v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil
+	}
+
+	if v.CanInterface() {
+		if val, ok := v.Interface().(Validator); ok {
+			return val.Validate()
+		}
+	}
+	if v.Kind() != reflect.Ptr && v.CanAddr() && v.Addr().CanInterface() {
+		if val, ok := v.Addr().Interface().(Validator); ok {
+			return val.Validate()
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package gli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// minWrapWidth is the narrowest width of wrapped text.
+const minWrapWidth = 20
+
+// helpWidth returns the width of help messages, or 0 for no wrapping.
+func (g App) helpWidth() int {
+	if g.HelpWidth != 0 {
+		if g.HelpWidth < 0 {
+			return 0
+		}
+		return g.HelpWidth
+	}
+
+	if cols, err := strconv.Atoi(strings.TrimSpace(os.Getenv("COLUMNS"))); err == nil && cols > 0 {
+		return cols
+	}
+
+	return 0
+}
+
+// writeWrapped writes prefix and text, wrapping text in width with hanging indentation.
+//
+//	prefix text text text
+//	       text text
+//
+// Lines in text are wrapped one by one. Zero width means no wrapping.
+func writeWrapped(w io.Writer, prefix, text string, width int) {
+	writeIndented(w, prefix, strings.Repeat(" ", displayWidth(prefix)), text, width)
+}
+
+// writeIndented is writeWrapped with indent for second and later lines.
+func writeIndented(w io.Writer, prefix, indent, text string, width int) {
+	lines := strings.Split(text, "\n")
+	if width > 0 {
+		avail := width - len(indent)
+		if avail < minWrapWidth {
+			avail = minWrapWidth
+		}
+
+		var wrapped []string
+		for _, l := range lines {
+			wrapped = append(wrapped, wrapText(l, avail)...)
+		}
+		lines = wrapped
+	}
+
+	for i, l := range lines {
+		if i == 0 {
+			fmt.Fprintf(w, "%s%s\n", prefix, l)
+		} else {
+			fmt.Fprintf(w, "%s%s\n", indent, l)
+		}
+	}
+}
+
+// wrapText splits s into lines not wider than width (display width).
+// Lines are broken at spaces, or between any runes of a too long word (like CJK text).
+// SGR sequences (colors) take no width.
+func wrapText(s string, width int) []string {
+	if displayWidth(s) <= width {
+		return []string{s}
+	}
+
+	var lines []string
+	var line strings.Builder
+	lineWidth := 0
+
+	flush := func() {
+		lines = append(lines, strings.TrimRight(line.String(), " "))
+		line.Reset()
+		lineWidth = 0
+	}
+
+	for _, word := range splitWords(s) {
+		ww := displayWidth(word)
+		isSpace := strings.TrimSpace(word) == ""
+
+		if lineWidth+ww <= width {
+			if !(isSpace && lineWidth == 0 && len(lines) > 0) {
+				line.WriteString(word)
+				lineWidth += ww
+			}
+			continue
+		}
+
+		if isSpace {
+			flush()
+			continue
+		}
+
+		if lineWidth > 0 && ww <= width {
+			flush()
+			line.WriteString(word)
+			lineWidth = ww
+			continue
+		}
+
+		// too long: break between runes
+		inSGR := false
+		for _, r := range word {
+			if r == '\x1b' || inSGR {
+				inSGR = r != 'm'
+				line.WriteRune(r)
+				continue
+			}
+
+			rw := runewidth.RuneWidth(r)
+			if lineWidth+rw > width && lineWidth > 0 {
+				flush()
+			}
+			line.WriteRune(r)
+			lineWidth += rw
+		}
+	}
+	if line.Len() > 0 {
+		flush()
+	}
+
+	return lines
+}
+
+// splitWords splits s into words and spaces.
+// Wide runes (CJK) are words by themselves, so that lines can be broken between them.
+func splitWords(s string) []string {
+	var words []string
+	var curr strings.Builder
+	currSpace := false
+
+	push := func() {
+		if curr.Len() > 0 {
+			words = append(words, curr.String())
+			curr.Reset()
+		}
+	}
+
+	for _, r := range s {
+		switch {
+		case unicode.IsSpace(r):
+			if !currSpace {
+				push()
+			}
+			currSpace = true
+			curr.WriteRune(r)
+		case runewidth.RuneWidth(r) > 1:
+			push()
+			currSpace = false
+			words = append(words, string(r))
+		default:
+			if currSpace {
+				push()
+			}
+			currSpace = false
+			curr.WriteRune(r)
+		}
+	}
+	push()
+
+	return words
+}